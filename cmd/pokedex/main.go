@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/joshhartwig/pokedex/internal/api"
 	"github.com/joshhartwig/pokedex/internal/database"
 	"github.com/joshhartwig/pokedex/internal/pokecache"
 	"github.com/joshhartwig/pokedex/internal/repl"
@@ -18,6 +19,14 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// cacheTTL is how long a cached response is used before it is revalidated, the api
+// data rarely changes so a day keeps repeat sessions off the network
+const cacheTTL = 24 * time.Hour
+
+// cacheLimits bounds the memory held by cached responses, a pokemon with all of its
+// moves and sprites is a few hundred KB so the least recently used are evicted
+var cacheLimits = pokecache.Limits{MaxBytes: 64 << 20, MaxEntries: 5000}
+
 func main() {
 	err := godotenv.Load()
 	if err != nil {
@@ -37,68 +46,154 @@ func main() {
 	dbQueries := database.New(db)
 
 	conf := models.Config{
-		Logger:     logger,
-		Db:         dbQueries,
-		BaseApiUrl: "https://pokeapi.co/api/v2/location-area/",
-		Cache:      *pokecache.NewCache(time.Millisecond * 10),
-		Pokedex:    map[string]models.Pokemon{},
+		Logger:  logger,
+		Db:      dbQueries,
+		Pokedex: map[string]models.Pokemon{},
+	}
+
+	// keep responses on disk or in the database so the next session does not download them again
+	conf.Cache, err = newCacheStore(os.Getenv("POKEDEX_CACHE"), dbQueries, logger)
+	if err != nil {
+		fmt.Printf("error setting up the cache: %v\n", err)
+		os.Exit(1)
+	}
+	defer conf.Cache.Close()
+
+	// every resource url is derived from the api root, point it at a mirror with POKEAPI_URL
+	opts := api.DefaultOptions()
+	if root := os.Getenv("POKEAPI_URL"); root != "" {
+		opts.Endpoints, err = api.NewEndpoints(root)
+		if err != nil {
+			fmt.Printf("error parsing POKEAPI_URL: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	conf.ApiRoot = opts.Endpoints.Root()
+
+	// serve the api from a snapshot directory instead of the network when offline
+	if dir := os.Getenv("POKEDEX_OFFLINE_DIR"); dir != "" {
+		logger.Info("running in offline mode", "snapshot", dir)
+		opts.Transport = api.NewSnapshotTransport(os.DirFS(dir), opts.Endpoints)
+		opts.RequestsPerSecond = 0 // nothing to protect when reading from disk
 	}
+	conf.Client = api.NewClient(conf.Cache, logger, opts)
 
 	// setup the commands
 	conf.Commands = map[string]models.CliCommand{
 		"exit": {
 			Name:        "exit",
 			Description: "used to close the app",
-			Callback:    func(args ...string) error { return repl.Exit(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Exit(ctx, &conf, args...) },
 		},
 		"help": {
 			Name:        "help",
 			Description: "used to get help",
-			Callback:    func(args ...string) error { return repl.Help(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Help(ctx, &conf, args...) },
 		},
 		"map": {
 			Name:        "map",
 			Description: "used to list all the pokedex locations",
-			Callback:    func(args ...string) error { return repl.Map(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Map(ctx, &conf, args...) },
 		},
 		"mapb": {
 			Name:        "mapb",
 			Description: "used to move forward in the map",
-			Callback:    func(args ...string) error { return repl.Mapb(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Mapb(ctx, &conf, args...) },
 		},
 		"explore": {
 			Name:        "explore",
 			Description: "explores a section of the map",
-			Callback:    func(args ...string) error { return repl.Explore(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Explore(ctx, &conf, args...) },
 		},
 		"catch": {
 			Name:        "catch",
 			Description: "attempts to catch a pokemon",
-			Callback:    func(args ...string) error { return repl.Catch(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Catch(ctx, &conf, args...) },
 		},
 		"inspect": {
 			Name:        "inspect",
 			Description: "inspects a caught pokemon",
-			Callback:    func(args ...string) error { return repl.Inspect(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Inspect(ctx, &conf, args...) },
+		},
+		"evolution": {
+			Name:        "evolution",
+			Description: "displays the evolution chain of a pokemon",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Evolution(ctx, &conf, args...) },
+		},
+		"types": {
+			Name:        "types",
+			Description: "displays the damage relations of a type",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Types(ctx, &conf, args...) },
+		},
+		"matchup": {
+			Name:        "matchup",
+			Description: "displays how effective a type is against a pokemon",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Matchup(ctx, &conf, args...) },
+		},
+		"moves": {
+			Name:        "moves",
+			Description: "displays the moves a pokemon can learn",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Moves(ctx, &conf, args...) },
+		},
+		"move": {
+			Name:        "move",
+			Description: "displays the details of a move",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Move(ctx, &conf, args...) },
+		},
+		"ability": {
+			Name:        "ability",
+			Description: "displays the details of an ability",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Ability(ctx, &conf, args...) },
+		},
+		"item": {
+			Name:        "item",
+			Description: "displays the details of an item",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Item(ctx, &conf, args...) },
+		},
+		"where": {
+			Name:        "where",
+			Description: "displays where a pokemon can be encountered",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Where(ctx, &conf, args...) },
+		},
+		"lang": {
+			Name:        "lang",
+			Description: "sets the language names are shown in",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Lang(ctx, &conf, args...) },
+		},
+		"cache": {
+			Name:        "cache",
+			Description: "inspects and clears the response cache",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Cache(ctx, &conf, args...) },
 		},
 		"pokedex": {
 			Name:        "pokedex",
 			Description: "displays your caught pokemons",
-			Callback:    func(args ...string) error { return repl.Pokedex(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Pokedex(ctx, &conf, args...) },
 		},
 		"history": {
 			Name:        "history",
 			Description: "display command line history for each command",
-			Callback:    func(args ...string) error { return repl.History(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.History(ctx, &conf, args...) },
+		},
+		"prefetch": {
+			Name:        "prefetch",
+			Description: "downloads every location area and pokemon into the cache",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Prefetch(ctx, &conf, args...) },
+		},
+		"snapshot": {
+			Name:        "snapshot",
+			Description: "writes the downloaded api data to a directory for offline mode",
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Snapshot(ctx, &conf, args...) },
 		},
 		"fight": {
 			Name:        "fight",
 			Description: "fight two pokemon that you have caught",
-			Callback:    func(args ...string) error { return repl.Fight(&conf, args...) },
+			Callback:    func(ctx context.Context, args ...string) error { return repl.Fight(ctx, &conf, args...) },
 		},
 	}
-	loadPokedexFromDb(&conf)
-	repl.Repl(&conf)
+	ctx := context.Background()
+	loadPokedexFromDb(ctx, &conf)
+	repl.Repl(ctx, &conf)
 }
 
 // loadPokedexFromDb loads Pokemon data from the database into the Pokedex cache.
@@ -108,12 +203,13 @@ func main() {
 // it will either return the error or log it and continue to the next Pokemon.
 //
 // Parameters:
+//   - ctx: The context used for the database query
 //   - c: A pointer to the Config struct containing the database connection and Pokedex map
 //
 // Returns:
 //   - error: Returns an error if the database query fails, nil otherwise
-func loadPokedexFromDb(c *models.Config) error {
-	pokemonRows, err := c.Db.ListPokemon(context.Background())
+func loadPokedexFromDb(ctx context.Context, c *models.Config) error {
+	pokemonRows, err := c.Db.ListPokemon(ctx)
 	if err != nil {
 		return err
 	}
@@ -130,3 +226,42 @@ func loadPokedexFromDb(c *models.Config) error {
 	}
 	return nil
 }
+
+// newCacheStore returns the cache backend named by kind, memory keeps responses for the
+// session only while disk (the default) and postgres keep them in memory and persist them.
+// When the disk directory is unusable the cache falls back to memory.
+func newCacheStore(kind string, q pokecache.CacheQuerier, logger *slog.Logger) (pokecache.Store, error) {
+	memory := pokecache.NewBoundedCache(cacheTTL, cacheLimits)
+	memory.Start()
+
+	switch kind {
+	case "memory":
+		return memory, nil
+	case "", "disk":
+		disk, err := newDiskStore()
+		if err != nil {
+			logger.Warn("disk cache disabled", "error", err)
+			return memory, nil
+		}
+		disk.Start()
+		return pokecache.NewTiered(memory, disk), nil
+	case "postgres":
+		return pokecache.NewTiered(memory, pokecache.NewPostgres(q, cacheTTL)), nil
+	default:
+		memory.Close()
+		return nil, fmt.Errorf("unknown POKEDEX_CACHE %q, use memory, disk or postgres", kind)
+	}
+}
+
+// newDiskStore returns a disk store in POKEDEX_CACHE_DIR or in the pokedex directory
+// under the user's cache dir
+func newDiskStore() (*pokecache.Disk, error) {
+	dir := os.Getenv("POKEDEX_CACHE_DIR")
+	if dir == "" {
+		var err error
+		if dir, err = pokecache.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	return pokecache.NewDisk(dir, cacheTTL)
+}
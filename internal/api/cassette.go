@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrUnrecordedRequest is returned by a replaying Cassette for a request it has no recording for
+var ErrUnrecordedRequest = errors.New("cassette: no recorded response for request")
+
+// CassetteMode selects if a Cassette records real responses or replays recorded ones
+type CassetteMode int
+
+const (
+	// ModeReplay serves recorded responses and fails every other request
+	ModeReplay CassetteMode = iota
+	// ModeRecord forwards requests to the real transport and records the responses
+	ModeRecord
+)
+
+// Interaction is a single recorded request and its response
+type Interaction struct {
+	Method     string          `json:"method"`
+	Url        string          `json:"url"`
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`      // json bodies are kept as is so fixtures stay readable
+	BodyText   string          `json:"body_text,omitempty"` // any other body, e.g. a plain text 404
+}
+
+// Cassette is an http.RoundTripper that records responses to a fixture file once and
+// replays them afterwards, so tests can exercise the client without a network.
+type Cassette struct {
+	path         string
+	mode         CassetteMode
+	next         http.RoundTripper
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewCassette returns a Cassette backed by the fixture file at path. In ModeReplay the
+// file must exist. In ModeRecord requests are sent through next, http.DefaultTransport
+// if nil, and Save writes the recordings to path.
+func NewCassette(path string, mode CassetteMode, next http.RoundTripper) (*Cassette, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c := &Cassette{path: path, mode: mode, next: next}
+	if mode == ModeRecord {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("cassette: decoding %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.mode == ModeRecord {
+		return c.record(req)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, in := range c.interactions {
+		if in.Method == req.Method && in.Url == req.URL.String() {
+			return in.response(req), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s (%s)", ErrUnrecordedRequest, req.Method, req.URL, c.path)
+}
+
+// record sends the request through the real transport and keeps the response
+func (c *Cassette) record(req *http.Request) (*http.Response, error) {
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Method:     req.Method,
+		Url:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     http.Header{},
+	}
+	for _, k := range []string{"Content-Type", "ETag", "Last-Modified", "Retry-After"} {
+		if v := resp.Header.Get(k); v != "" {
+			in.Header.Set(k, v)
+		}
+	}
+	if json.Valid(body) {
+		in.Body = body
+	} else {
+		in.BodyText = string(body)
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, in)
+	c.mu.Unlock()
+
+	return in.response(req), nil
+}
+
+// Save writes the recorded interactions to the fixture file, it does nothing when replaying
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// response builds the http.Response for a recorded interaction
+func (in Interaction) response(req *http.Request) *http.Response {
+	body := []byte(in.Body)
+	if in.BodyText != "" {
+		body = []byte(in.BodyText)
+	}
+	header := in.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return newResponse(req, in.StatusCode, header, body)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/missing" {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"count":3}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	// record the responses once
+	recorder, err := NewCassette(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := newTestClient(t)
+	c.httpClient.Transport = recorder
+	if _, err := getPage(ctx, c, srv.URL+"/areas"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := getPage(ctx, c, srv.URL+"/missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got %v want %v", err, ErrNotFound)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	// replay them without touching the server
+	srv.Close()
+	player, err := NewCassette(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c = newTestClient(t)
+	c.httpClient.Transport = player
+
+	ah, err := getPage(ctx, c, srv.URL+"/areas")
+	if err != nil || ah.Count != 3 {
+		t.Errorf("got %v, %v want the recorded page", ah, err)
+	}
+	if entry, _ := c.cache.Get(srv.URL + "/areas"); entry.ETag != `"v1"` {
+		t.Errorf("got etag %q want the recorded one", entry.ETag)
+	}
+	if _, err := getPage(ctx, c, srv.URL+"/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v want %v", err, ErrNotFound)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("got %d requests to the server want 2", hits.Load())
+	}
+}
+
+func TestCassetteUnrecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, _ := NewCassette(path, ModeRecord, nil)
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	player, err := NewCassette(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := newTestClient(t)
+	c.httpClient.Transport = player
+	retries := 0
+	c.sleep = func(context.Context, time.Duration) error {
+		retries++
+		return nil
+	}
+
+	if _, err := c.GetPokemon(context.Background(), "pikachu"); !errors.Is(err, ErrUnrecordedRequest) {
+		t.Errorf("got %v want %v", err, ErrUnrecordedRequest)
+	}
+	if retries != 0 {
+		t.Errorf("got %d retries want 0", retries)
+	}
+}
+
+func TestCassetteMissingFile(t *testing.T) {
+	if _, err := NewCassette(filepath.Join(t.TempDir(), "nope.json"), ModeReplay, nil); err == nil {
+		t.Errorf("expected an error replaying a missing cassette")
+	}
+}
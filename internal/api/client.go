@@ -2,50 +2,154 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"io"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/joshhartwig/pokedex/internal/pokecache"
 	"github.com/joshhartwig/pokedex/pkg/models"
 )
 
-// FetchFromCache checks if the url is in the cache, if it is it will decode the
-// if the url is not found in the cache it will download the data and add the
-// url and associated data encoded in json format to the map and return it
-func FetchFromCache(c *models.Config, url string, v any) error {
+// BaseUrl is the root of the public PokeAPI, used unless Options.Endpoints points elsewhere
+const BaseUrl = "https://pokeapi.co/api/v2/"
+
+// Client is the typed PokeAPI client the commands talk to, see models.ApiClient
+type Client = models.ApiClient
+
+// PokeClient is the http implementation of Client, responses are stored in the cache
+// keyed by url so repeat calls do not hit the network
+type PokeClient struct {
+	cache      pokecache.Store
+	logger     *slog.Logger
+	httpClient *http.Client
+	opts       Options
+	inflight   group                                      // coalesces concurrent downloads of the same url
+	limiter    *limiter                                   // client side rate limit, nil when disabled
+	sleep      func(context.Context, time.Duration) error // waits between retries, replaced in tests
+}
+
+// NewClient returns a PokeClient that reads and writes through the given cache
+func NewClient(cache pokecache.Store, logger *slog.Logger, opts Options) *PokeClient {
+	return &PokeClient{
+		cache:      cache,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: opts.Timeout, Transport: opts.Transport},
+		opts:       opts,
+		limiter:    newLimiter(opts.RequestsPerSecond, opts.Burst),
+		sleep:      sleepContext,
+	}
+}
+
+// ListLocationAreas returns the page of location areas the cursor points at
+func (c *PokeClient) ListLocationAreas(ctx context.Context, page models.PageCursor) (models.Apiheader, error) {
+	var ah models.Apiheader
+	err := c.fetch(ctx, c.opts.Endpoints.LocationAreas(page.Offset, page.Size()), &ah)
+	return ah, err
+}
+
+// GetLocationArea returns a single location area by name or id
+func (c *PokeClient) GetLocationArea(ctx context.Context, name string) (models.LocationArea, error) {
+	var la models.LocationArea
+	err := c.fetch(ctx, c.opts.Endpoints.LocationArea(name), &la)
+	return la, err
+}
+
+// GetPokemon returns a single pokemon by name or id
+func (c *PokeClient) GetPokemon(ctx context.Context, name string) (models.Pokemon, error) {
+	var p models.Pokemon
+	err := c.fetch(ctx, c.opts.Endpoints.Pokemon(name), &p)
+	return p, err
+}
+
+// GetPokemonSpecies returns the species of a pokemon by name or id
+func (c *PokeClient) GetPokemonSpecies(ctx context.Context, name string) (models.PokemonSpecies, error) {
+	var ps models.PokemonSpecies
+	err := c.fetch(ctx, c.opts.Endpoints.Species(name), &ps)
+	return ps, err
+}
+
+// GetType returns a single type by name or id
+func (c *PokeClient) GetType(ctx context.Context, name string) (models.Type, error) {
+	var t models.Type
+	err := c.fetch(ctx, c.opts.Endpoints.Type(name), &t)
+	return t, err
+}
+
+// GetMove returns a single move by name or id
+func (c *PokeClient) GetMove(ctx context.Context, name string) (models.Move, error) {
+	var m models.Move
+	err := c.fetch(ctx, c.opts.Endpoints.Move(name), &m)
+	return m, err
+}
+
+// GetAbility returns a single ability by name or id
+func (c *PokeClient) GetAbility(ctx context.Context, name string) (models.Ability, error) {
+	var a models.Ability
+	err := c.fetch(ctx, c.opts.Endpoints.Ability(name), &a)
+	return a, err
+}
+
+// GetItem returns a single item by name or id
+func (c *PokeClient) GetItem(ctx context.Context, name string) (models.Item, error) {
+	var i models.Item
+	err := c.fetch(ctx, c.opts.Endpoints.Item(name), &i)
+	return i, err
+}
+
+// fetch checks if the url is in the cache, if it is fresh it will decode the cached data into v.
+// A stale entry with validators is revalidated with a conditional request and reused on a 304,
+// otherwise the data is downloaded, added to the cache and decoded into v.
+// Concurrent fetches of the same url share a single download.
+func (c *PokeClient) fetch(ctx context.Context, url string, v any) error {
 	// try to find the url in cache 1st
-	found, ok := c.Cache.Entries[url]
-	if !ok { // if we did not find it, add a new cache entry with the data
-		client := &http.Client{}
-		resp, err := client.Get(url)
-		if err != nil {
-			c.Logger.Error("error fetching url", "url", url, "error", err)
-			return err
-		}
-		defer resp.Body.Close()
+	entry, ok := c.cache.Get(url)
+	data := entry.Val
+	if !ok || !entry.IsFresh(time.Now()) { // if we did not find it or it is stale, ask the server
+		var err error
+		var shared bool
+		data, err, shared = c.inflight.do(ctx, url, func(ctx context.Context) ([]byte, error) {
+			resp, err := c.get(ctx, url, entry)
+			if err != nil {
+				return nil, err
+			}
+
+			if resp.notModified {
+				c.logger.Debug("cached entry revalidated", "url", url)
+				entry.CreatedAt = time.Now()
+				c.store(url, entry)
+				return entry.Val, nil
+			}
 
-		// convert the resp.body to byte slide
-		data, err := io.ReadAll(resp.Body)
+			// add to cache
+			c.store(url, pokecache.CacheEntry{
+				Val:          resp.body,
+				ETag:         resp.etag,
+				LastModified: resp.lastModified,
+			})
+			return resp.body, nil
+		})
 		if err != nil {
-			c.Logger.Error("error reading response body", "url", url, "error", err)
 			return err
 		}
-
-		// add to cache
-		c.Cache.Add(url, data)
-
-		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
-			c.Logger.Error("error decoding response body", "url", url, "error", err)
-			return err
+		if shared {
+			c.logger.Debug("shared in flight request", "url", url)
 		}
-		return nil
 	}
 
-	// we found the url in the cache, return the data
-	if err := json.NewDecoder(bytes.NewReader(found.Val)).Decode(&v); err != nil {
-		c.Logger.Error("error decoding cached response", "url", url, "error", err)
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		c.logger.Error("error decoding response body", "url", url, "error", err)
 		return err
 	}
 
 	return nil
 }
+
+// store adds the entry to the cache, a failed write is logged and otherwise ignored
+// since the response is already in hand
+func (c *PokeClient) store(url string, e pokecache.CacheEntry) {
+	if err := c.cache.Add(url, e); err != nil {
+		c.logger.Warn("error writing cache entry", "url", url, "error", err)
+	}
+}
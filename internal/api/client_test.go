@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+func newTestClient(t *testing.T) *PokeClient {
+	t.Helper()
+	cache := pokecache.NewCache(time.Minute)
+	c := NewClient(cache, slog.New(slog.NewTextHandler(io.Discard, nil)), DefaultOptions())
+	c.sleep = func(context.Context, time.Duration) error { return nil }
+	return c
+}
+
+// getPage fetches an arbitrary url through the client and decodes it as a list page
+func getPage(ctx context.Context, c *PokeClient, url string) (models.Apiheader, error) {
+	var ah models.Apiheader
+	err := c.fetch(ctx, url, &ah)
+	return ah, err
+}
+
+func TestFetchStatusErrors(t *testing.T) {
+	cases := []struct {
+		status int
+		want   error
+	}{
+		{status: http.StatusNotFound, want: ErrNotFound},
+		{status: http.StatusTooManyRequests, want: ErrRateLimited},
+		{status: http.StatusInternalServerError, want: ErrUpstream},
+		{status: http.StatusBadRequest, want: ErrUpstream},
+	}
+
+	for _, tt := range cases {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, http.StatusText(tt.status), tt.status)
+			}))
+			defer srv.Close()
+
+			c := newTestClient(t)
+			_, err := getPage(context.Background(), c, srv.URL)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v want %v", err, tt.want)
+			}
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("got %v want a StatusError with code %d", err, tt.status)
+			}
+
+			if _, ok := c.cache.Get(srv.URL); ok {
+				t.Errorf("error response for %d was cached", tt.status)
+			}
+		})
+	}
+}
+
+func TestFetchCachesSuccess(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{"count":1,"results":[{"name":"canalave-city-area"}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	for i := 0; i < 2; i++ {
+		ah, err := getPage(context.Background(), c, srv.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ah.Results) != 1 || ah.Results[0].Name != "canalave-city-area" {
+			t.Errorf("got %v want one canalave-city-area result", ah.Results)
+		}
+	}
+
+	if hits != 1 {
+		t.Errorf("got %d requests want 1", hits)
+	}
+}
+
+// expire makes the cached entry for url stale without waiting for its ttl
+func expire(c *PokeClient, url string) {
+	e, ok := c.cache.Get(url)
+	if !ok {
+		return
+	}
+	e.CreatedAt = e.CreatedAt.Add(-2 * e.TTL)
+	c.cache.Add(url, e)
+}
+
+func TestRevalidateWithETag(t *testing.T) {
+	const etag = `W/"abc123"`
+	var full, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"count":7}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	for i := 0; i < 3; i++ {
+		expire(c, srv.URL)
+		ah, err := getPage(context.Background(), c, srv.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ah.Count != 7 {
+			t.Errorf("got count %d want 7", ah.Count)
+		}
+	}
+
+	if full != 1 || notModified != 2 {
+		t.Errorf("got %d full and %d not modified responses want 1 and 2", full, notModified)
+	}
+
+	// a revalidated entry is fresh again
+	entry, _ := c.cache.Get(srv.URL)
+	if !entry.IsFresh(time.Now()) {
+		t.Errorf("expected the entry to be fresh after a 304")
+	}
+}
+
+func TestRevalidateWithLastModified(t *testing.T) {
+	const lastModified = "Mon, 02 Jun 2025 10:00:00 GMT"
+	var full, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(`{"count":7}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	for i := 0; i < 2; i++ {
+		expire(c, srv.URL)
+		if _, err := getPage(context.Background(), c, srv.URL); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if full != 1 || notModified != 1 {
+		t.Errorf("got %d full and %d not modified responses want 1 and 1", full, notModified)
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Endpoints builds every resource url from a single api root, so the client can be
+// pointed at the public PokeAPI, a self hosted mirror or a local stand in.
+type Endpoints struct {
+	root *url.URL
+}
+
+// NewEndpoints parses root, e.g. https://pokeapi.co/api/v2/, into Endpoints
+func NewEndpoints(root string) (Endpoints, error) {
+	u, err := url.Parse(root)
+	if err != nil {
+		return Endpoints{}, fmt.Errorf("invalid api root %q: %w", root, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return Endpoints{}, fmt.Errorf("invalid api root %q: must be an absolute http(s) url", root)
+	}
+
+	// resources are resolved below the root, drop anything that is not part of it
+	u.RawQuery = ""
+	u.Fragment = ""
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		u.RawPath = ""
+	}
+	return Endpoints{root: u}, nil
+}
+
+// DefaultEndpoints returns the Endpoints of the public PokeAPI
+func DefaultEndpoints() Endpoints {
+	e, err := NewEndpoints(BaseUrl)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Root returns the api root url
+func (e Endpoints) Root() string {
+	return e.root.String()
+}
+
+// LocationAreas returns the url of a page of location areas, the query is written in the
+// same order the api uses for its own next and previous links so both share a cache entry
+func (e Endpoints) LocationAreas(offset, limit int) string {
+	return fmt.Sprintf("%s?offset=%d&limit=%d", e.resource("location-area", ""), offset, limit)
+}
+
+// LocationArea returns the url of a single location area
+func (e Endpoints) LocationArea(name string) string {
+	return e.resource("location-area", name)
+}
+
+// Pokemon returns the url of a single pokemon
+func (e Endpoints) Pokemon(name string) string {
+	return e.resource("pokemon", name)
+}
+
+// Species returns the url of a single pokemon species
+func (e Endpoints) Species(name string) string {
+	return e.resource("pokemon-species", name)
+}
+
+// Type returns the url of a single type
+func (e Endpoints) Type(name string) string {
+	return e.resource("type", name)
+}
+
+// Move returns the url of a single move
+func (e Endpoints) Move(name string) string {
+	return e.resource("move", name)
+}
+
+// Ability returns the url of a single ability
+func (e Endpoints) Ability(name string) string {
+	return e.resource("ability", name)
+}
+
+// Item returns the url of a single item
+func (e Endpoints) Item(name string) string {
+	return e.resource("item", name)
+}
+
+// Link maps a url found in an api response onto the configured root. Responses from a
+// mirror can still carry links to the public api, so an absolute link is kept only if
+// it is below the root, otherwise its path below /api/v2/ is rebased onto the root.
+// Relative links are resolved against the root.
+func (e Endpoints) Link(link string) (string, error) {
+	if link == "" {
+		return "", errors.New("empty resource url")
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource url %q: %w", link, err)
+	}
+
+	if !u.IsAbs() {
+		return e.root.ResolveReference(u).String(), nil
+	}
+	if u.Host == e.root.Host && strings.HasPrefix(u.Path, e.root.Path) {
+		return u.String(), nil
+	}
+
+	_, rel, ok := strings.Cut(u.Path, "/api/v2/")
+	if !ok {
+		return "", fmt.Errorf("resource url %q is not an api url", link)
+	}
+	rebased := e.root.JoinPath(rel)
+	if strings.HasSuffix(u.Path, "/") && !strings.HasSuffix(rebased.Path, "/") {
+		rebased.Path += "/"
+	}
+	rebased.RawQuery = u.RawQuery
+	return rebased.String(), nil
+}
+
+// resource returns the url of a resource list, or of a single named resource when
+// name is set. Names are path escaped so user input can not walk out of the resource.
+func (e Endpoints) resource(resource, name string) string {
+	if name == "" {
+		return e.root.JoinPath(resource + "/").String()
+	}
+	return e.root.JoinPath(resource, escapeName(name)+"/").String()
+}
+
+// escapeName path escapes a resource name, including the dot segments that url paths
+// would otherwise resolve
+func escapeName(name string) string {
+	if strings.Trim(name, ".") == "" {
+		return strings.ReplaceAll(name, ".", "%2E")
+	}
+	return url.PathEscape(name)
+}
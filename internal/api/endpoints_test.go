@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+func TestEndpoints(t *testing.T) {
+	e, err := NewEndpoints("http://mirror.local:8000/api/v2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		got    string
+		expect string
+	}{
+		{got: e.Root(), expect: "http://mirror.local:8000/api/v2/"},
+		{got: e.LocationAreas(20, 20), expect: "http://mirror.local:8000/api/v2/location-area/?offset=20&limit=20"},
+		{got: e.LocationArea("canalave-city-area"), expect: "http://mirror.local:8000/api/v2/location-area/canalave-city-area/"},
+		{got: e.Pokemon("pikachu"), expect: "http://mirror.local:8000/api/v2/pokemon/pikachu/"},
+		{got: e.Species("25"), expect: "http://mirror.local:8000/api/v2/pokemon-species/25/"},
+		{got: e.Type("electric"), expect: "http://mirror.local:8000/api/v2/type/electric/"},
+		{got: e.Move("thunder-shock"), expect: "http://mirror.local:8000/api/v2/move/thunder-shock/"},
+		{got: e.Ability("static"), expect: "http://mirror.local:8000/api/v2/ability/static/"},
+		{got: e.Item("oran-berry"), expect: "http://mirror.local:8000/api/v2/item/oran-berry/"},
+		{got: e.Pokemon("../type/fire"), expect: "http://mirror.local:8000/api/v2/pokemon/..%2Ftype%2Ffire/"},
+		{got: e.Pokemon(".."), expect: "http://mirror.local:8000/api/v2/pokemon/%2E%2E/"},
+	}
+
+	for _, tt := range cases {
+		if tt.got != tt.expect {
+			t.Errorf("got %s want %s", tt.got, tt.expect)
+		}
+	}
+}
+
+func TestNewEndpointsInvalid(t *testing.T) {
+	for _, root := range []string{"", "pokeapi.co/api/v2", "ftp://pokeapi.co/api/v2/", "http://"} {
+		if _, err := NewEndpoints(root); err == nil {
+			t.Errorf("expected an error for root %q", root)
+		}
+	}
+}
+
+func TestClientUsesMirror(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		w.Write([]byte(`{"name":"pikachu"}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	e, err := NewEndpoints(srv.URL + "/pokeapi/api/v2/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.opts.Endpoints = e
+
+	ctx := context.Background()
+	if _, err := c.GetPokemon(ctx, "pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.GetLocationArea(ctx, "canalave-city-area"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.ListLocationAreas(ctx, models.PageCursor{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"/pokeapi/api/v2/pokemon/pikachu/",
+		"/pokeapi/api/v2/location-area/canalave-city-area/",
+		"/pokeapi/api/v2/location-area/?offset=0&limit=20",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("got paths %v want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("got path %s want %s", paths[i], want[i])
+		}
+	}
+}
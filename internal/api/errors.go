@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sentinel errors for non 2xx responses, use errors.Is to check for them
+var (
+	ErrNotFound    = errors.New("resource not found")
+	ErrRateLimited = errors.New("rate limited by the api")
+	ErrUpstream    = errors.New("upstream api error")
+)
+
+// StatusError is returned when the api responds with a non 2xx status code.
+// It unwraps to ErrNotFound for a 404, ErrRateLimited for a 429 and ErrUpstream for
+// anything else.
+type StatusError struct {
+	Url        string
+	StatusCode int
+	RetryAfter time.Duration // parsed Retry-After header, zero if absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", e.Url, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Unwrap returns the sentinel error matching the status code
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return ErrUpstream
+	}
+}
+
+// checkStatus returns a *StatusError if the response status is not 2xx
+func checkStatus(resp *http.Response, url string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return &StatusError{
+		Url:        url,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
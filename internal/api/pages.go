@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"iter"
+
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// LocationAreas iterates over every location area from the page of start onwards,
+// fetching the next page as the loop reaches it. A page that fails to load is yielded
+// as an error and ends the iteration.
+//
+//	for loc, err := range api.LocationAreas(ctx, c, models.PageCursor{}) {
+//		...
+//	}
+func LocationAreas(ctx context.Context, c Client, start models.PageCursor) iter.Seq2[models.Location, error] {
+	return func(yield func(models.Location, error) bool) {
+		for page := start; ; page = page.Next() {
+			ah, err := c.ListLocationAreas(ctx, page)
+			if err != nil {
+				yield(models.Location{}, err)
+				return
+			}
+
+			for _, l := range ah.Results {
+				if !yield(l, nil) {
+					return
+				}
+			}
+
+			if ah.Next == "" || len(ah.Results) == 0 {
+				return
+			}
+		}
+	}
+}
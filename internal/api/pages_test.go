@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// pagedServer serves five location areas two at a time, offset 4 fails when broken is set
+func pagedServer(t *testing.T, broken bool) (*httptest.Server, *[]string) {
+	t.Helper()
+	names := []string{"a", "b", "c", "d", "e"}
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		var offset, limit int
+		fmt.Sscanf(r.URL.Query().Get("offset"), "%d", &offset)
+		fmt.Sscanf(r.URL.Query().Get("limit"), "%d", &limit)
+		if broken && offset == 4 {
+			http.Error(w, "boom", http.StatusNotFound)
+			return
+		}
+
+		ah := models.Apiheader{Count: len(names)}
+		for i := offset; i < min(offset+limit, len(names)); i++ {
+			ah.Results = append(ah.Results, models.Location{Name: names[i]})
+		}
+		if offset+limit < len(names) {
+			ah.Next = fmt.Sprintf("next?offset=%d&limit=%d", offset+limit, limit)
+		}
+		json.NewEncoder(w).Encode(ah)
+	}))
+	return srv, &requested
+}
+
+func newPagedClient(t *testing.T, srv *httptest.Server) *PokeClient {
+	t.Helper()
+	c := newTestClient(t)
+	e, err := NewEndpoints(srv.URL + "/api/v2/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.opts.Endpoints = e
+	return c
+}
+
+func TestLocationAreasIterator(t *testing.T) {
+	srv, requested := pagedServer(t, false)
+	defer srv.Close()
+	c := newPagedClient(t, srv)
+
+	var got []string
+	for l, err := range LocationAreas(context.Background(), c, models.PageCursor{Limit: 2}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, l.Name)
+	}
+
+	if !slices.Equal(got, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("got %v want every location", got)
+	}
+	want := []string{
+		"/api/v2/location-area/?offset=0&limit=2",
+		"/api/v2/location-area/?offset=2&limit=2",
+		"/api/v2/location-area/?offset=4&limit=2",
+	}
+	if !slices.Equal(*requested, want) {
+		t.Errorf("got requests %v want %v", *requested, want)
+	}
+}
+
+func TestLocationAreasIteratorStartAndBreak(t *testing.T) {
+	srv, requested := pagedServer(t, false)
+	defer srv.Close()
+	c := newPagedClient(t, srv)
+
+	var got []string
+	for l, err := range LocationAreas(context.Background(), c, models.PageCursor{Offset: 2, Limit: 2}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, l.Name)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	if !slices.Equal(got, []string{"c", "d"}) {
+		t.Errorf("got %v want [c d]", got)
+	}
+	if len(*requested) != 1 {
+		t.Errorf("got requests %v want only the starting page", *requested)
+	}
+}
+
+func TestLocationAreasIteratorError(t *testing.T) {
+	srv, _ := pagedServer(t, true)
+	defer srv.Close()
+	c := newPagedClient(t, srv)
+
+	var got []string
+	var gotErr error
+	for l, err := range LocationAreas(context.Background(), c, models.PageCursor{Limit: 2}) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, l.Name)
+	}
+
+	if !slices.Equal(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("got %v want the locations before the broken page", got)
+	}
+	if !errors.Is(gotErr, ErrNotFound) {
+		t.Errorf("got %v want %v", gotErr, ErrNotFound)
+	}
+}
+
+func TestPageCursor(t *testing.T) {
+	p := models.PageCursor{}
+	if p.Size() != models.DefaultPageLimit || !p.IsFirst() {
+		t.Errorf("got %+v want the first page of the default size", p)
+	}
+
+	p = p.Next().Next()
+	if p.Offset != 40 || p.Limit != 20 || p.IsFirst() {
+		t.Errorf("got %+v want offset 40 limit 20", p)
+	}
+
+	p = models.PageCursor{Offset: 5, Limit: 10}.Prev()
+	if p.Offset != 0 || !p.IsFirst() {
+		t.Errorf("got %+v want the previous page clamped to the first", p)
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// PrefetchOptions configures Prefetch
+type PrefetchOptions struct {
+	Workers   int                             // number of concurrent downloads, defaults to 4
+	Endpoints Endpoints                       // used to find the cache key of each resource
+	Cache     pokecache.Store                 // pokemon fresh in the cache are not downloaded again
+	Progress  func(progress PrefetchProgress) // optional, called after every resource
+}
+
+// PrefetchProgress reports how far along a Prefetch is
+type PrefetchProgress struct {
+	Stage   string // pages, areas or pokemon
+	Done    int    // resources handled in this stage
+	Total   int    // resources in this stage, zero while the pages are walked
+	Areas   int    // location areas handled
+	Pokemon int    // pokemon handled
+	Skipped int    // pokemon that were fresh in the cache and not downloaded
+	Failed  int    // resources that could not be fetched
+}
+
+// Prefetch warms the cache with every location area and every pokemon that can be
+// encountered in them. It walks all location area pages, then fetches the areas and
+// the pokemon with a bounded pool of workers. Pokemon that are fresh in the cache are
+// skipped so an interrupted prefetch picks up where it left off, pages and areas are
+// always read through the client which serves the fresh ones from the cache without a
+// request. Failures of single resources are counted and do not stop the crawl, a
+// cancelled ctx does.
+func Prefetch(ctx context.Context, c Client, opts PrefetchOptions) (PrefetchProgress, error) {
+	if opts.Workers < 1 {
+		opts.Workers = 4
+	}
+	p := &prefetcher{client: c, opts: opts}
+
+	areas, err := p.pages(ctx)
+	if err != nil {
+		return p.progress, err
+	}
+
+	// every area lists the pokemon found there, collect them without duplicates
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	var pokemon []string
+	err = p.run(ctx, "areas", areas, func(ctx context.Context, name string) (bool, error) {
+		// the encounters are needed either way, a cached area is served from the cache
+		la, err := c.GetLocationArea(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, enc := range la.PokemonEncounters {
+			if !seen[enc.Pokemon.Name] {
+				seen[enc.Pokemon.Name] = true
+				pokemon = append(pokemon, enc.Pokemon.Name)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return p.progress, err
+	}
+
+	err = p.run(ctx, "pokemon", pokemon, func(ctx context.Context, name string) (bool, error) {
+		if p.fresh(opts.Endpoints.Pokemon(name)) {
+			return true, nil
+		}
+		_, err := c.GetPokemon(ctx, name)
+		return false, err
+	})
+	return p.progress, err
+}
+
+// prefetcher holds the shared state of a single Prefetch
+type prefetcher struct {
+	client   Client
+	opts     PrefetchOptions
+	mu       sync.Mutex
+	progress PrefetchProgress
+}
+
+// pages walks the location area pages by following Apiheader.Next from the first page
+// and returns the area names
+func (p *prefetcher) pages(ctx context.Context) ([]string, error) {
+	page, err := p.client.ListLocationAreas(ctx, models.PageCursor{})
+	var areas []string
+	for {
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range page.Results {
+			areas = append(areas, l.Name)
+		}
+		p.update(func(pr *PrefetchProgress) {
+			pr.Stage = "pages"
+			pr.Done = len(areas)
+		})
+
+		if page.Next == "" || len(page.Results) == 0 {
+			return areas, nil
+		}
+		next := page.Next
+		page = models.Apiheader{}
+		err = p.client.Fetch(ctx, next, &page)
+	}
+}
+
+// fresh reports if the resource at key is in the cache and has not expired
+func (p *prefetcher) fresh(key string) bool {
+	if p.opts.Cache == nil {
+		return false
+	}
+	e, ok := p.opts.Cache.Peek(key)
+	return ok && e.IsFresh(time.Now())
+}
+
+// run hands every name to fn using the worker pool, fn reports whether it skipped the
+// download
+func (p *prefetcher) run(ctx context.Context, stage string, names []string, fn func(ctx context.Context, name string) (skipped bool, err error)) error {
+	p.update(func(pr *PrefetchProgress) {
+		pr.Stage = stage
+		pr.Done = 0
+		pr.Total = len(names)
+	})
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < p.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				skipped, err := fn(ctx, name)
+
+				p.update(func(pr *PrefetchProgress) {
+					pr.Done++
+					if stage == "areas" {
+						pr.Areas++
+					} else {
+						pr.Pokemon++
+					}
+					if skipped {
+						pr.Skipped++
+					}
+					if err != nil && ctx.Err() == nil {
+						pr.Failed++
+					}
+				})
+			}
+		}()
+	}
+
+	// feed the workers until every name is queued or the prefetch is cancelled
+feed:
+	for _, name := range names {
+		select {
+		case jobs <- name:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// update changes the progress and reports it
+func (p *prefetcher) update(fn func(*PrefetchProgress)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(&p.progress)
+	if p.opts.Progress != nil {
+		p.opts.Progress(p.progress)
+	}
+}
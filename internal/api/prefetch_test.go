@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+)
+
+// crawlServer serves two pages of location areas and the pokemon found in them
+func crawlServer(t *testing.T) (*httptest.Server, func() map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	hits := map[string]int{}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.RequestURI()]++
+		mu.Unlock()
+
+		root := srv.URL + "/api/v2/"
+		switch r.URL.RequestURI() {
+		case "/api/v2/location-area/?offset=0&limit=20":
+			fmt.Fprintf(w, `{"count":21,"next":"%slocation-area/?offset=20&limit=20","results":[{"name":"area-a"},{"name":"area-b"}]}`, root)
+		case "/api/v2/location-area/?offset=20&limit=20":
+			fmt.Fprint(w, `{"count":3,"results":[{"name":"area-c"}]}`)
+		case "/api/v2/location-area/area-a/":
+			fmt.Fprint(w, `{"name":"area-a","pokemon_encounters":[{"pokemon":{"name":"tentacool"}},{"pokemon":{"name":"magikarp"}}]}`)
+		case "/api/v2/location-area/area-b/":
+			fmt.Fprint(w, `{"name":"area-b","pokemon_encounters":[{"pokemon":{"name":"magikarp"}},{"pokemon":{"name":"missingno"}}]}`)
+		case "/api/v2/location-area/area-c/":
+			fmt.Fprint(w, `{"name":"area-c","pokemon_encounters":[{"pokemon":{"name":"gyarados"}}]}`)
+		default:
+			name, ok := strings.CutPrefix(r.URL.Path, "/api/v2/pokemon/")
+			if !ok || name == "missingno/" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintf(w, `{"name":%q}`, strings.TrimSuffix(name, "/"))
+		}
+	}))
+
+	return srv, func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		copied := map[string]int{}
+		for k, v := range hits {
+			copied[k] = v
+		}
+		return copied
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	srv, hits := crawlServer(t)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	e, err := NewEndpoints(srv.URL + "/api/v2/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.opts.Endpoints = e
+	opts := PrefetchOptions{Workers: 3, Endpoints: e, Cache: c.cache}
+
+	got, err := Prefetch(context.Background(), c, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := PrefetchProgress{Stage: "pokemon", Done: 4, Total: 4, Areas: 3, Pokemon: 4, Skipped: 0, Failed: 1}
+	if got != want {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+	for uri, n := range hits() {
+		if n != 1 {
+			t.Errorf("got %d requests for %s want 1", n, uri)
+		}
+	}
+
+	// a second run finds everything but the failed pokemon in the cache
+	before := hits()
+	got, err = Prefetch(context.Background(), c, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Skipped != 3 || got.Failed != 1 {
+		t.Errorf("got %+v want 3 skipped and 1 failed", got)
+	}
+	after := hits()
+	for uri, n := range after {
+		if n != before[uri] && uri != "/api/v2/pokemon/missingno/" {
+			t.Errorf("got %d new requests for %s want 0", n-before[uri], uri)
+		}
+	}
+}
+
+func TestPrefetchRefreshesStale(t *testing.T) {
+	srv, hits := crawlServer(t)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	e, _ := NewEndpoints(srv.URL + "/api/v2/")
+	c.opts.Endpoints = e
+
+	stale := pokecache.CacheEntry{CreatedAt: time.Now().Add(-time.Hour), TTL: time.Minute, Val: []byte(`{"name":"gyarados"}`)}
+	if err := c.cache.Add(e.Pokemon("gyarados"), stale); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := Prefetch(context.Background(), c, PrefetchOptions{Workers: 2, Endpoints: e, Cache: c.cache})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Skipped != 0 {
+		t.Errorf("got %d skipped want 0, an expired pokemon must be downloaded again", got.Skipped)
+	}
+	if n := hits()["/api/v2/pokemon/gyarados/"]; n != 1 {
+		t.Errorf("got %d requests for the expired pokemon want 1", n)
+	}
+}
+
+func TestPrefetchCancelled(t *testing.T) {
+	srv, hits := crawlServer(t)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	e, _ := NewEndpoints(srv.URL + "/api/v2/")
+	c.opts.Endpoints = e
+
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := func(p PrefetchProgress) {
+		// stop as soon as the pages are walked
+		if p.Stage == "areas" {
+			cancel()
+		}
+	}
+
+	_, err := Prefetch(ctx, c, PrefetchOptions{Workers: 1, Endpoints: e, Cache: c.cache, Progress: progress})
+	if err != context.Canceled {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+	if n := hits()["/api/v2/pokemon/gyarados/"]; n != 0 {
+		t.Errorf("got %d requests for pokemon after cancelling want 0", n)
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket shared by every network request of a client. It holds up
+// to burst tokens and refills at rate tokens per second, a request takes one token
+// and waits for it when the bucket is empty.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// newLimiter returns a limiter allowing rps requests per second with bursts of up to
+// burst requests, a non positive rps disables limiting and returns nil
+func newLimiter(rps float64, burst int) *limiter {
+	if rps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait before it may be
+// used. The bucket can go negative so waiting callers queue up in order.
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel hands back a reserved token that was never used
+func (l *limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// wait blocks until a token is available or ctx is done and returns how long it waited,
+// a nil limiter never waits
+func (l *limiter) wait(ctx context.Context, sleep func(context.Context, time.Duration) error) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	delay := l.reserve()
+	if delay == 0 {
+		return 0, nil
+	}
+	if err := sleep(ctx, delay); err != nil {
+		l.cancel()
+		return 0, err
+	}
+	return delay, nil
+}
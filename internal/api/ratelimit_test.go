@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(10, 2)
+	l.now = func() time.Time { return now }
+
+	// the burst is free, after that each token takes 100ms to refill
+	want := []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i, w := range want {
+		if got := l.reserve(); got != w {
+			t.Errorf("reserve %d: got %v want %v", i, got, w)
+		}
+	}
+
+	// after a second the bucket is full again, but never more than the burst
+	now = now.Add(time.Second)
+	want = []time.Duration{0, 0, 100 * time.Millisecond}
+	for i, w := range want {
+		if got := l.reserve(); got != w {
+			t.Errorf("reserve after refill %d: got %v want %v", i, got, w)
+		}
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := newLimiter(0, 5)
+	if l != nil {
+		t.Fatalf("expected a nil limiter for a zero rate")
+	}
+	waited, err := l.wait(context.Background(), sleepContext)
+	if waited != 0 || err != nil {
+		t.Errorf("got %v, %v want 0, nil", waited, err)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := newLimiter(1, 1)
+	l.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.wait(ctx, sleepContext); err != context.Canceled {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+
+	// the cancelled reservation is handed back
+	if l.tokens < -0.01 || l.tokens > 0.01 {
+		t.Errorf("got %v tokens want 0", l.tokens)
+	}
+}
+
+func TestRateLimitOnlyNetwork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count":1}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	c.limiter = newLimiter(1, 1)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c.limiter.now = func() time.Time { return now }
+
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		// the same url over and over is served from the cache and never waits
+		if _, err := getPage(ctx, c, srv.URL); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(waits) != 0 {
+		t.Errorf("got waits %v for cache hits want none", waits)
+	}
+
+	// new urls hit the network and share the single token bucket
+	for i := 0; i < 2; i++ {
+		if _, err := getPage(ctx, c, fmt.Sprintf("%s/?page=%d", srv.URL, i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != 2*time.Second {
+		t.Errorf("got waits %v want [1s 2s]", waits)
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// Resolve follows a NamedResource link, fetching the resource it points to through the
+// client cache and decoding it into T, e.g. Resolve[models.Pokemon](ctx, c, encounter.Pokemon)
+func Resolve[T any](ctx context.Context, c Client, r models.NamedResource) (T, error) {
+	var v T
+	if r.URL == "" {
+		return v, fmt.Errorf("resource %q has no url", r.Name)
+	}
+	err := c.Fetch(ctx, r.URL, &v)
+	return v, err
+}
+
+// Fetch decodes the api resource at url into v through the cache, url is usually a link
+// from another resource and is mapped onto the configured api root first
+func (c *PokeClient) Fetch(ctx context.Context, url string, v any) error {
+	link, err := c.opts.Endpoints.Link(url)
+	if err != nil {
+		return err
+	}
+	return c.fetch(ctx, link, v)
+}
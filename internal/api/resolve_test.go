@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+func TestEndpointsLink(t *testing.T) {
+	e, err := NewEndpoints("http://mirror.local/pokeapi/api/v2/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		input  string
+		expect string
+		ok     bool
+	}{
+		{input: "http://mirror.local/pokeapi/api/v2/pokemon/25/", expect: "http://mirror.local/pokeapi/api/v2/pokemon/25/", ok: true},
+		{input: "https://pokeapi.co/api/v2/pokemon-species/25/", expect: "http://mirror.local/pokeapi/api/v2/pokemon-species/25/", ok: true},
+		{input: "https://pokeapi.co/api/v2/location-area/?offset=20&limit=20", expect: "http://mirror.local/pokeapi/api/v2/location-area/?offset=20&limit=20", ok: true},
+		{input: "move/84/", expect: "http://mirror.local/pokeapi/api/v2/move/84/", ok: true},
+		{input: "https://example.com/elsewhere", ok: false},
+		{input: "", ok: false},
+	}
+
+	for _, tt := range cases {
+		got, err := e.Link(tt.input)
+		if (err == nil) != tt.ok || got != tt.expect {
+			t.Errorf("Link(%q) got %q, %v want %q", tt.input, got, err, tt.expect)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path != "/api/v2/pokemon/72/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name":"tentacool","base_experience":67}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	e, err := NewEndpoints(srv.URL + "/api/v2/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.opts.Endpoints = e
+
+	// links in the data point at the public api, they are followed on the configured root
+	link := models.NamedResource{Name: "tentacool", URL: "https://pokeapi.co/api/v2/pokemon/72/"}
+	for i := 0; i < 2; i++ {
+		p, err := Resolve[models.Pokemon](context.Background(), c, link)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Name != "tentacool" || p.BaseExperience != 67 {
+			t.Errorf("got %+v want tentacool", p)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("got %d requests want 1, the second resolve should use the cache", hits)
+	}
+
+	if _, err := Resolve[models.Pokemon](context.Background(), c, models.NamedResource{Name: "nowhere"}); err == nil {
+		t.Errorf("expected an error resolving a resource without a url")
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+)
+
+// Options configures the transport used by the PokeClient
+type Options struct {
+	Timeout    time.Duration     // timeout for a single http request
+	MaxRetries int               // number of retries after the first attempt
+	BaseDelay  time.Duration     // delay before the first retry, doubled on each retry
+	MaxDelay   time.Duration     // upper bound for the backoff delay
+	Transport  http.RoundTripper // optional, e.g. NewSnapshotTransport for offline mode
+	Endpoints  Endpoints         // api root every resource url is built from
+
+	// client side rate limit shared by all network requests, cache hits are not limited.
+	// A RequestsPerSecond of zero disables the limit.
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// DefaultOptions returns the options used to talk to the public PokeAPI
+func DefaultOptions() Options {
+	return Options{
+		Timeout:    10 * time.Second,
+		MaxRetries: 3,
+		BaseDelay:  250 * time.Millisecond,
+		MaxDelay:   5 * time.Second,
+		Endpoints:  DefaultEndpoints(),
+
+		RequestsPerSecond: 10,
+		Burst:             10,
+	}
+}
+
+// response is a downloaded body along with the validators the server sent for it
+type response struct {
+	body         []byte
+	etag         string
+	lastModified string
+	notModified  bool // the server answered 304, the cached body is still valid
+}
+
+// get downloads the url, retrying connection errors, 5xx and 429 responses up to
+// MaxRetries times with a jittered exponential backoff. A Retry-After header on a
+// 429 response takes precedence over the backoff delay, a server asking to wait longer
+// than MaxDelay gets its error returned right away instead of blocking the command.
+// If cached has validators the request is made conditional.
+func (c *PokeClient) get(ctx context.Context, url string, cached pokecache.CacheEntry) (response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, url, cached)
+		if err == nil {
+			return resp, nil
+		}
+
+		if attempt >= c.opts.MaxRetries || ctx.Err() != nil || !retryable(err) {
+			return response{}, err
+		}
+
+		delay := c.backoff(attempt)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			if statusErr.RetryAfter > c.opts.MaxDelay {
+				c.logger.Info("not retrying, server asked to wait too long", "url", url, "retry_after", statusErr.RetryAfter, "max_delay", c.opts.MaxDelay)
+				return response{}, err
+			}
+			delay = statusErr.RetryAfter
+		}
+
+		c.logger.Info("retrying request", "url", url, "retry", attempt+1, "max_retries", c.opts.MaxRetries, "delay", delay, "error", err)
+		if err := c.sleep(ctx, delay); err != nil {
+			return response{}, err
+		}
+	}
+}
+
+// do performs a single request and returns the body of a 2xx response, or a
+// notModified response if the server confirmed the cached entry is still valid
+func (c *PokeClient) do(ctx context.Context, url string, cached pokecache.CacheEntry) (response, error) {
+	waited, err := c.limiter.wait(ctx, c.sleep)
+	if err != nil {
+		return response{}, err
+	}
+	if waited > 0 {
+		c.logger.Debug("rate limited request", "url", url, "wait", waited)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return response{}, err
+	}
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Error("error fetching url", "url", url, "error", err)
+		return response{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached.HasValidators() {
+		return response{notModified: true}, nil
+	}
+
+	// never return an error body, it would end up in the cache and fail decoding on every later call
+	if err := checkStatus(resp, url); err != nil {
+		c.logger.Debug("unexpected response status", "url", url, "status", resp.StatusCode)
+		return response{}, err
+	}
+
+	// convert the resp.body to byte slice
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("error reading response body", "url", url, "error", err)
+		return response{}, err
+	}
+	return response{
+		body:         data,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// backoff returns the delay before the given retry, the exponential delay is capped at
+// MaxDelay and jittered between half and the full value so clients do not retry in lockstep
+func (c *PokeClient) backoff(attempt int) time.Duration {
+	delay := c.opts.MaxDelay
+	if attempt < 32 && c.opts.BaseDelay<<attempt < c.opts.MaxDelay {
+		delay = c.opts.BaseDelay << attempt
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// sleepContext waits for d or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryable reports if the request that returned err is worth retrying, rate limits
+// and 5xx responses are, other status errors are not. Anything else is a connection
+// or read error and is retried as well, except for a cassette missing a recording.
+func retryable(err error) bool {
+	if errors.Is(err, ErrUnrecordedRequest) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+// parseRetryAfter parses a Retry-After header given in either seconds or as an http date
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer fails the first n requests with status and then succeeds
+func failingServer(n int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= n {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{"count":1}`))
+	}))
+	return srv, &hits
+}
+
+func TestRetryRecovers(t *testing.T) {
+	srv, hits := failingServer(2, http.StatusServiceUnavailable, nil)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	var delays []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	ah, err := getPage(context.Background(), c, srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ah.Count != 1 {
+		t.Errorf("got count %d want 1", ah.Count)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("got %d requests want 3", hits.Load())
+	}
+	if len(delays) != 2 {
+		t.Fatalf("got %d delays want 2", len(delays))
+	}
+
+	// each delay is jittered between half and the full exponential value
+	for i, d := range delays {
+		full := c.opts.BaseDelay << i
+		if d < full/2 || d > full {
+			t.Errorf("retry %d: got delay %v want between %v and %v", i, d, full/2, full)
+		}
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	srv, hits := failingServer(100, http.StatusBadGateway, nil)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	_, err := getPage(context.Background(), c, srv.URL)
+	if !errors.Is(err, ErrUpstream) {
+		t.Errorf("got %v want %v", err, ErrUpstream)
+	}
+
+	want := int32(c.opts.MaxRetries + 1)
+	if hits.Load() != want {
+		t.Errorf("got %d requests want %d", hits.Load(), want)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv, hits := failingServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"3"}})
+	defer srv.Close()
+
+	c := newTestClient(t)
+	var delays []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	if _, err := getPage(context.Background(), c, srv.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("got %d requests want 2", hits.Load())
+	}
+	if !slices.Equal(delays, []time.Duration{3 * time.Second}) {
+		t.Errorf("got delays %v want [3s]", delays)
+	}
+}
+
+func TestRetryAfterLongerThanMaxDelay(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC().Format(http.TimeFormat)
+	for _, retryAfter := range []string{"86400", future} {
+		srv, hits := failingServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": {retryAfter}})
+
+		c := newTestClient(t)
+		slept := false
+		c.sleep = func(context.Context, time.Duration) error {
+			slept = true
+			return nil
+		}
+
+		if _, err := getPage(context.Background(), c, srv.URL); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Retry-After %s: got %v want %v", retryAfter, err, ErrRateLimited)
+		}
+		if hits.Load() != 1 || slept {
+			t.Errorf("Retry-After %s: got %d requests and slept %t, want 1 request and no wait", retryAfter, hits.Load(), slept)
+		}
+		srv.Close()
+	}
+}
+
+func TestNoRetryOnNotFound(t *testing.T) {
+	srv, hits := failingServer(100, http.StatusNotFound, nil)
+	defer srv.Close()
+
+	c := newTestClient(t)
+	if _, err := getPage(context.Background(), c, srv.URL); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v want %v", err, ErrNotFound)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("got %d requests want 1", hits.Load())
+	}
+}
+
+func TestRetryConnectionError(t *testing.T) {
+	srv, _ := failingServer(0, http.StatusOK, nil)
+	url := srv.URL
+	srv.Close()
+
+	c := newTestClient(t)
+	retries := 0
+	c.sleep = func(context.Context, time.Duration) error {
+		retries++
+		return nil
+	}
+
+	if _, err := getPage(context.Background(), c, url); err == nil {
+		t.Errorf("expected an error from a closed server")
+	}
+	if retries != c.opts.MaxRetries {
+		t.Errorf("got %d retries want %d", retries, c.opts.MaxRetries)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		input  string
+		expect time.Duration
+	}{
+		{input: "", expect: 0},
+		{input: "3", expect: 3 * time.Second},
+		{input: "-1", expect: 0},
+		{input: now.Add(90 * time.Second).Format(http.TimeFormat), expect: 90 * time.Second},
+		{input: "soon", expect: 0},
+	}
+
+	for _, tt := range cases {
+		got := parseRetryAfter(tt.input, now)
+		if got != tt.expect {
+			t.Errorf("parseRetryAfter(%q) got %v want %v", tt.input, got, tt.expect)
+		}
+	}
+}
+
+func TestCancelHungRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	if _, err := getPage(ctx, c, srv.URL); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// call is a single in flight download shared by every caller asking for the same url
+type call struct {
+	done    chan struct{}
+	val     []byte
+	err     error
+	dups    int                // callers that joined after the first
+	waiters int                // callers still waiting on the result
+	cancel  context.CancelFunc // cancels the download once every waiter has gone
+}
+
+// group coalesces concurrent downloads of the same url so they share a single
+// http request and result
+type group struct {
+	mu sync.Mutex
+	m  map[string]*call
+}
+
+// do runs fn for key unless a call for key is already in flight, in which case it
+// waits for that call and returns its result. shared reports if the result was
+// handed to more than one caller. A caller whose ctx is done stops waiting right
+// away, the download itself is only cancelled once no callers are left.
+func (g *group) do(ctx context.Context, key string, fn func(context.Context) ([]byte, error)) (val []byte, err error, shared bool) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	cl, ok := g.m[key]
+	if ok {
+		cl.dups++
+		cl.waiters++
+	} else {
+		// the download must outlive the first caller if others are still waiting on it
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.m[key] = cl
+
+		go func() {
+			cl.val, cl.err = fn(flightCtx)
+			g.mu.Lock()
+			if g.m[key] == cl {
+				delete(g.m, key)
+			}
+			g.mu.Unlock()
+			cancel()
+			close(cl.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		g.mu.Lock()
+		shared = cl.dups > 0
+		g.mu.Unlock()
+		return cl.val, cl.err, shared
+	case <-ctx.Done():
+		g.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			// nobody wants the result anymore, later callers start a fresh download
+			cl.cancel()
+			if g.m[key] == cl {
+				delete(g.m, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err(), false
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForDups blocks until n callers have joined the in flight call for key
+func waitForDups(t *testing.T, g *group, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		cl, ok := g.m[key]
+		dups := 0
+		if ok {
+			dups = cl.dups
+		}
+		g.mu.Unlock()
+		if dups >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers to join %s", n, key)
+}
+
+func TestConcurrentFetchSharesRequest(t *testing.T) {
+	const callers = 10
+
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		w.Write([]byte(`{"count":42}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+
+	var wg sync.WaitGroup
+	counts := make([]int, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ah, err := getPage(context.Background(), c, srv.URL)
+			counts[i] = ah.Count
+			errs[i] = err
+		}(i)
+	}
+
+	waitForDups(t, &c.inflight, srv.URL, callers-1)
+	close(release)
+	wg.Wait()
+
+	if hits.Load() != 1 {
+		t.Errorf("got %d requests want 1", hits.Load())
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Errorf("caller %d: unexpected error: %v", i, errs[i])
+		}
+		if counts[i] != 42 {
+			t.Errorf("caller %d: got count %d want 42", i, counts[i])
+		}
+	}
+}
+
+func TestGroupWaiterCancel(t *testing.T) {
+	var g group
+	release := make(chan struct{})
+	var flightErr atomic.Value
+
+	fn := func(ctx context.Context) ([]byte, error) {
+		select {
+		case <-release:
+			return []byte("done"), nil
+		case <-ctx.Done():
+			flightErr.Store(ctx.Err())
+			return nil, ctx.Err()
+		}
+	}
+
+	// the first caller gives up, the second still gets the shared result
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err, _ := g.do(ctx, "key", fn)
+		first <- err
+	}()
+
+	second := make(chan []byte, 1)
+	go func() {
+		val, _, _ := g.do(context.Background(), "key", fn)
+		second <- val
+	}()
+	waitForDups(t, &g, "key", 1)
+
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if val := <-second; string(val) != "done" {
+		t.Errorf("got %q want %q", val, "done")
+	}
+	if err := flightErr.Load(); err != nil {
+		t.Errorf("shared call was cancelled with a waiter left: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+)
+
+// A snapshot is a directory of json files laid out like the api, relative to the api root:
+//
+//	location-area/index@limit=20&offset=0.json    a page of location areas, keyed by its query
+//	location-area/canalave-city-area.json         a single location area
+//	pokemon/pikachu.json                          a single pokemon
+//
+// The files hold the exact bodies the api returned, so they decode into the same
+// models.Apiheader, models.LocationArea and models.Pokemon shapes.
+
+// snapshotTransport is an http.RoundTripper that answers requests from a snapshot
+type snapshotTransport struct {
+	fsys fs.FS
+	root string // path of the api root, e.g. /api/v2/
+}
+
+// NewSnapshotTransport returns an http.RoundTripper that serves requests below the
+// endpoints root from the snapshot in fsys instead of the network, requests for files
+// that are not in the snapshot get a 404. Set it as Options.Transport to run the client
+// offline.
+func NewSnapshotTransport(fsys fs.FS, e Endpoints) http.RoundTripper {
+	return &snapshotTransport{fsys: fsys, root: e.root.Path}
+}
+
+func (t *snapshotTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return snapshotResponse(req, http.StatusMethodNotAllowed, nil), nil
+	}
+
+	name, ok := snapshotPath(t.root, req.URL)
+	if !ok {
+		return snapshotResponse(req, http.StatusNotFound, nil), nil
+	}
+
+	data, err := fs.ReadFile(t.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+		return snapshotResponse(req, http.StatusNotFound, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshotResponse(req, http.StatusOK, data), nil
+}
+
+// snapshotResponse builds the http.Response for a snapshot lookup
+func snapshotResponse(req *http.Request, status int, body []byte) *http.Response {
+	header := http.Header{}
+	if body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+	return newResponse(req, status, header, body)
+}
+
+// newResponse builds an in memory http.Response for transports that do not touch the network
+func newResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// WriteSnapshot writes every response below the endpoints root held in the cache to dir
+// using the snapshot layout, it returns the number of files written. Entries from other
+// hosts or that are not api resources are skipped.
+func WriteSnapshot(cache pokecache.Store, endpoints Endpoints, dir string) (int, error) {
+	written := 0
+	var writeErr error
+
+	err := cache.Range(func(key string, e pokecache.CacheEntry) bool {
+		u, err := url.Parse(key)
+		if err != nil || u.Host != endpoints.root.Host {
+			return true
+		}
+		name, ok := snapshotPath(endpoints.root.Path, u)
+		if !ok {
+			return true
+		}
+
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			writeErr = err
+			return false
+		}
+		if err := os.WriteFile(file, e.Val, 0o644); err != nil {
+			writeErr = err
+			return false
+		}
+		written++
+		return true
+	})
+	if err != nil {
+		return written, err
+	}
+
+	return written, writeErr
+}
+
+// snapshotPath maps an api url to its file in a snapshot, ok is false for urls
+// outside of the api root
+func snapshotPath(root string, u *url.URL) (name string, ok bool) {
+	rel, ok := strings.CutPrefix(u.Path, root)
+	if !ok {
+		return "", false
+	}
+	rel = strings.Trim(rel, "/")
+	if rel == "" {
+		return "", false
+	}
+
+	// a list endpoint has no name of its own, its pages are told apart by their query
+	if !strings.Contains(rel, "/") {
+		rel += "/index"
+	}
+	if q := u.Query(); len(q) > 0 {
+		rel += "@" + q.Encode()
+	}
+
+	name = path.Clean(rel) + ".json"
+	if !fs.ValidPath(name) {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+func TestSnapshotPath(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+		ok     bool
+	}{
+		{input: BaseUrl + "location-area/", expect: "location-area/index.json", ok: true},
+		{input: BaseUrl + "location-area/?offset=20&limit=20", expect: "location-area/index@limit=20&offset=20.json", ok: true},
+		{input: BaseUrl + "location-area/canalave-city-area/", expect: "location-area/canalave-city-area.json", ok: true},
+		{input: BaseUrl + "pokemon/pikachu", expect: "pokemon/pikachu.json", ok: true},
+		{input: BaseUrl + "pokemon/../../../etc/passwd", ok: false},
+		{input: BaseUrl, ok: false},
+		{input: "https://example.com/other/thing", ok: false},
+	}
+
+	for _, tt := range cases {
+		u, err := url.Parse(tt.input)
+		if err != nil {
+			t.Fatalf("bad test url %s: %v", tt.input, err)
+		}
+		got, ok := snapshotPath(DefaultEndpoints().root.Path, u)
+		if ok != tt.ok || got != tt.expect {
+			t.Errorf("snapshotPath(%s) got %q, %v want %q, %v", tt.input, got, ok, tt.expect, tt.ok)
+		}
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	live := pokecache.NewCache(time.Minute)
+	live.Add(BaseUrl+"location-area/?offset=0&limit=1", pokecache.CacheEntry{Val: []byte(`{"count":2,"next":"` + BaseUrl + `location-area/?offset=1&limit=1","results":[{"name":"canalave-city-area"}]}`)})
+	live.Add(BaseUrl+"location-area/?offset=1&limit=1", pokecache.CacheEntry{Val: []byte(`{"count":2,"results":[{"name":"eterna-city-area"}]}`)})
+	live.Add(BaseUrl+"location-area/canalave-city-area/", pokecache.CacheEntry{Val: []byte(`{"name":"canalave-city-area","pokemon_encounters":[{"pokemon":{"name":"tentacool"}}]}`)})
+	live.Add(BaseUrl+"pokemon/pikachu/", pokecache.CacheEntry{Val: []byte(`{"name":"pikachu","base_experience":112}`)})
+	live.Add("https://example.com/not-the-api", pokecache.CacheEntry{Val: []byte(`nope`)})
+
+	dir := t.TempDir()
+	written, err := WriteSnapshot(live, DefaultEndpoints(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error writing snapshot: %v", err)
+	}
+	if written != 4 {
+		t.Errorf("got %d files written want 4", written)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pokemon", "pikachu.json")); err != nil {
+		t.Errorf("expected pokemon/pikachu.json in the snapshot: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Transport = NewSnapshotTransport(os.DirFS(dir), DefaultEndpoints())
+	c := NewClient(pokecache.NewCache(time.Minute), slog.New(slog.NewTextHandler(io.Discard, nil)), opts)
+	ctx := context.Background()
+
+	page := models.PageCursor{Limit: 1}
+	ah, err := c.ListLocationAreas(ctx, page)
+	if err != nil || len(ah.Results) != 1 || ah.Results[0].Name != "canalave-city-area" {
+		t.Errorf("got %v, %v want the first page", ah, err)
+	}
+
+	ah, err = c.ListLocationAreas(ctx, page.Next())
+	if err != nil || len(ah.Results) != 1 || ah.Results[0].Name != "eterna-city-area" {
+		t.Errorf("got %v, %v want the second page", ah, err)
+	}
+
+	la, err := c.GetLocationArea(ctx, "canalave-city-area")
+	if err != nil || len(la.PokemonEncounters) != 1 {
+		t.Errorf("got %v, %v want canalave-city-area", la, err)
+	}
+
+	p, err := c.GetPokemon(ctx, "pikachu")
+	if err != nil || p.BaseExperience != 112 {
+		t.Errorf("got %v, %v want pikachu", p, err)
+	}
+
+	if _, err := c.GetPokemon(ctx, "mew"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v want %v", err, ErrNotFound)
+	}
+}
@@ -11,6 +11,15 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+type CacheEntry struct {
+	Key          string
+	Val          []byte
+	Etag         string
+	LastModified string
+	CreatedAt    time.Time
+	TtlMs        int64
+}
+
 type Pokemon struct {
 	ID          uuid.UUID
 	PokemonName string
@@ -0,0 +1,222 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Disk is a persistent Store that keeps one file per key in a directory, so cached
+// responses survive restarts. Each file holds the entry value along with its metadata.
+// Once started the files of expired entries are pruned in the background.
+type Disk struct {
+	dir       string
+	ttl       time.Duration
+	now       func() time.Time // the clock entries are created and pruned by, replaced in tests
+	done      chan struct{}
+	pruner    sync.WaitGroup
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+// diskEntry is the file format of a disk entry, the key is kept so a file can be told
+// apart from another key with the same hash
+type diskEntry struct {
+	Key          string        `json:"key"`
+	CreatedAt    time.Time     `json:"created_at"`
+	TTL          time.Duration `json:"ttl"`
+	ETag         string        `json:"etag,omitempty"`
+	LastModified string        `json:"last_modified,omitempty"`
+	Val          []byte        `json:"val"`
+}
+
+// DefaultDir returns the pokedex directory under the user's cache dir, e.g. ~/.cache/pokedex
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pokedex"), nil
+}
+
+// NewDisk returns a disk store keeping its files in dir, dir is created if needed.
+// Entries added without a TTL get ttl.
+func NewDisk(dir string, ttl time.Duration) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Disk{dir: dir, ttl: ttl, now: time.Now, done: make(chan struct{})}, nil
+}
+
+// WithClock replaces the clock of the store, used to test expiry without waiting. It
+// must be called before the store is used.
+func (d *Disk) WithClock(now func() time.Time) *Disk {
+	d.now = now
+	return d
+}
+
+// Start prunes the directory once in the background so files left by earlier sessions
+// do not pile up, starting an already started store does nothing
+func (d *Disk) Start() {
+	d.startOnce.Do(func() {
+		d.pruner.Add(1)
+		go func() {
+			defer d.pruner.Done()
+			// best effort, a file that can not be removed is tried again next session
+			_, _ = d.prune(d.done)
+		}()
+	})
+}
+
+// Prune removes the files of expired entries, validators or not, along with files that
+// can not be read and returns how many it removed. Nothing else bounds the directory,
+// so an expired entry is downloaded again rather than revalidated after a prune.
+func (d *Disk) Prune() (int, error) {
+	return d.prune(nil)
+}
+
+// prune is Prune stopping early once done is closed
+func (d *Disk) prune(done <-chan struct{}) (int, error) {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	now := d.now()
+	removed := 0
+	var errs []error
+	for _, file := range files {
+		select {
+		case <-done:
+			return removed, errors.Join(errs...)
+		default:
+		}
+
+		if de, ok := d.read(file); ok && de.entry().IsFresh(now) {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// Get returns the entry stored for the key, expired entries are returned as well so the
+// caller can decide to revalidate them
+func (d *Disk) Get(key string) (CacheEntry, bool) {
+	de, ok := d.read(d.path(key))
+	if !ok || de.Key != key {
+		return CacheEntry{}, false
+	}
+	return de.entry(), true
+}
+
+// Peek is Get, reading a file has no side effects
+func (d *Disk) Peek(key string) (CacheEntry, bool) {
+	return d.Get(key)
+}
+
+// Add stores the entry for the key, overwriting any existing one. The file is written
+// next to its final path and renamed so a reader never sees a partial entry.
+func (d *Disk) Add(key string, e CacheEntry) error {
+	e = stamp(e, d.ttl, d.now())
+	data, err := json.Marshal(diskEntry{
+		Key:          key,
+		CreatedAt:    e.CreatedAt,
+		TTL:          e.TTL,
+		ETag:         e.ETag,
+		LastModified: e.LastModified,
+		Val:          e.Val,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+// Delete removes the entry for the key, deleting a missing key is not an error
+func (d *Disk) Delete(key string) error {
+	err := os.Remove(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Range calls fn for each entry on disk until fn returns false, unreadable files are skipped
+func (d *Disk) Range(fn func(key string, e CacheEntry) bool) error {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		de, ok := d.read(file)
+		if !ok {
+			continue
+		}
+		if !fn(de.Key, de.entry()) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Close stops a running prune and waits for it, every entry is written when it is added
+func (d *Disk) Close() error {
+	d.closeOnce.Do(func() { close(d.done) })
+	d.pruner.Wait()
+	return nil
+}
+
+// read decodes an entry file, ok is false when it is missing or corrupt
+func (d *Disk) read(file string) (diskEntry, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return diskEntry{}, false
+	}
+	var de diskEntry
+	if err := json.Unmarshal(data, &de); err != nil {
+		return diskEntry{}, false
+	}
+	return de, true
+}
+
+// entry converts the file format back into a cache entry
+func (de diskEntry) entry() CacheEntry {
+	return CacheEntry{
+		CreatedAt:    de.CreatedAt,
+		TTL:          de.TTL,
+		Val:          de.Val,
+		ETag:         de.ETag,
+		LastModified: de.LastModified,
+	}
+}
+
+// path returns the file of a key, keys are urls so they are hashed into a safe file name
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
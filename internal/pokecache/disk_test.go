@@ -0,0 +1,156 @@
+package pokecache
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDiskSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDisk(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cache := NewTiered(NewCache(time.Hour), disk)
+	if err := cache.Add("https://example.com/pokemon/1/", CacheEntry{Val: []byte("bulbasaur"), ETag: `"v1"`}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a new cache on the same directory starts with what the previous one stored
+	reopened, err := NewDisk(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restarted := NewTiered(NewCache(time.Hour), reopened)
+
+	e, ok := restarted.Get("https://example.com/pokemon/1/")
+	if !ok {
+		t.Fatalf("expected to find entry on disk")
+	}
+	if string(e.Val) != "bulbasaur" || e.ETag != `"v1"` || e.TTL != time.Hour {
+		t.Errorf("got %+v", e)
+	}
+	if !e.IsFresh(time.Now()) {
+		t.Errorf("expected entry to be fresh")
+	}
+}
+
+func TestDiskPerEntryTTL(t *testing.T) {
+	clock := newFakeClock()
+	disk, err := NewDisk(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.WithClock(clock.Now)
+	cache := NewTiered(NewCache(time.Hour).WithClock(clock.Now), disk).WithClock(clock.Now)
+
+	// written by an earlier session that used a short ttl
+	disk.Add("expired", CacheEntry{TTL: time.Minute, Val: []byte("old")})
+	disk.Add("validated", CacheEntry{TTL: time.Minute, Val: []byte("old"), ETag: `"v1"`})
+	disk.Add("fresh", CacheEntry{TTL: 10 * time.Minute, Val: []byte("fresh")})
+	clock.Advance(2 * time.Minute)
+
+	if _, ok := cache.Get("expired"); ok {
+		t.Errorf("expected expired entry to be dropped")
+	}
+	if _, err := os.Stat(disk.path("expired")); !os.IsNotExist(err) {
+		t.Errorf("expected expired entry file to be removed, got %v", err)
+	}
+
+	e, ok := cache.Get("validated")
+	if !ok {
+		t.Fatalf("expected entry with validators to be kept for revalidation")
+	}
+	if e.IsFresh(clock.Now()) {
+		t.Errorf("expected entry with validators to be stale")
+	}
+
+	e, ok = cache.Get("fresh")
+	if !ok || !e.IsFresh(clock.Now()) {
+		t.Errorf("expected entry inside its own ttl to be fresh, got %+v", e)
+	}
+}
+
+func TestDiskDeleteAndRange(t *testing.T) {
+	disk, err := NewDisk(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := disk.Add(key, CacheEntry{Val: []byte(key)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := disk.Delete("b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := disk.Delete("b"); err != nil {
+		t.Errorf("expected deleting a missing key to succeed, got %v", err)
+	}
+
+	got := map[string]string{}
+	if err := disk.Range(func(key string, e CacheEntry) bool {
+		got[key] = string(e.Val)
+		return true
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got["a"] != "a" || got["c"] != "c" {
+		t.Errorf("got %v want a and c", got)
+	}
+}
+
+func TestDiskPrune(t *testing.T) {
+	clock := newFakeClock()
+	disk, err := NewDisk(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.WithClock(clock.Now)
+
+	disk.Add("expired", CacheEntry{TTL: time.Minute, Val: []byte("old")})
+	disk.Add("validated", CacheEntry{TTL: time.Minute, Val: []byte("old"), ETag: `"v1"`})
+	disk.Add("fresh", CacheEntry{Val: []byte("fresh")})
+	clock.Advance(2 * time.Minute)
+	if err := os.WriteFile(disk.path("corrupt"), []byte("{"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	removed, err := disk.Prune()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("got %d removed want 3", removed)
+	}
+	for _, key := range []string{"expired", "validated", "corrupt"} {
+		if _, err := os.Stat(disk.path(key)); !os.IsNotExist(err) {
+			t.Errorf("expected the file of %s to be removed, got %v", key, err)
+		}
+	}
+	if _, ok := disk.Get("fresh"); !ok {
+		t.Errorf("expected the fresh entry to be kept")
+	}
+}
+
+func TestDiskStartPrunes(t *testing.T) {
+	disk, err := NewDisk(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.Add("expired", CacheEntry{CreatedAt: time.Now().Add(-2 * time.Hour), Val: []byte("old")})
+
+	disk.Start()
+	disk.Start()
+	disk.pruner.Wait()
+
+	if _, ok := disk.Get("expired"); ok {
+		t.Errorf("expected the expired entry to be pruned")
+	}
+	if err := disk.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
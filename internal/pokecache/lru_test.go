@@ -0,0 +1,111 @@
+package pokecache
+
+import (
+	"testing"
+	"time"
+)
+
+// checkBytes compares the bytes the cache accounts for with the size of what it holds
+func checkBytes(t *testing.T, c *Cache, want int) {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	held := 0
+	for k, el := range c.entries {
+		held += entrySize(k, el.Value.(*item).entry)
+	}
+	if c.bytes != held {
+		t.Errorf("accounted for %d bytes but holds %d", c.bytes, held)
+	}
+	if c.bytes != want {
+		t.Errorf("got %d bytes want %d", c.bytes, want)
+	}
+}
+
+func TestByteAccountingOverwrites(t *testing.T) {
+	c := NewCache(time.Hour)
+	defer c.Close()
+
+	// the key "k" accounts for one byte
+	c.Add("k", CacheEntry{Val: []byte("1234567890")})
+	checkBytes(t, c, 11)
+
+	c.Add("k", CacheEntry{Val: []byte("12345")})
+	checkBytes(t, c, 6)
+
+	c.Add("k", CacheEntry{Val: []byte("12345678901234567890"), ETag: `"v1"`})
+	checkBytes(t, c, 25)
+
+	c.Add("other", CacheEntry{Val: []byte("abc")})
+	checkBytes(t, c, 33)
+
+	c.Delete("k")
+	checkBytes(t, c, 8)
+
+	c.Delete("k")
+	checkBytes(t, c, 8)
+}
+
+func TestMaxEntriesEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewBoundedCache(time.Hour, Limits{MaxEntries: 2})
+	defer c.Close()
+
+	c.Add("a", CacheEntry{Val: []byte("a")})
+	c.Add("b", CacheEntry{Val: []byte("b")})
+	c.Get("a") // b is now the least recently used
+	c.Add("c", CacheEntry{Val: []byte("c")})
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+	checkBytes(t, c, 4)
+}
+
+func TestMaxBytesEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewBoundedCache(time.Hour, Limits{MaxBytes: 30})
+	defer c.Close()
+
+	c.Add("a", CacheEntry{Val: make([]byte, 9)})
+	c.Add("b", CacheEntry{Val: make([]byte, 9)})
+	c.Add("c", CacheEntry{Val: make([]byte, 9)})
+	checkBytes(t, c, 30)
+
+	// growing b past the budget pushes out a, the least recently used
+	c.Add("b", CacheEntry{Val: make([]byte, 14)})
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+	checkBytes(t, c, 25)
+
+	// shrinking an entry makes room without evicting anything
+	c.Add("b", CacheEntry{Val: make([]byte, 4)})
+	c.Add("d", CacheEntry{Val: make([]byte, 9)})
+	for _, key := range []string{"b", "c", "d"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+	checkBytes(t, c, 25)
+}
+
+func TestOversizedEntryIsNotKept(t *testing.T) {
+	c := NewBoundedCache(time.Hour, Limits{MaxBytes: 10})
+	defer c.Close()
+
+	c.Add("small", CacheEntry{Val: []byte("a")})
+	c.Add("big", CacheEntry{Val: make([]byte, 20)})
+
+	if _, ok := c.Get("big"); ok {
+		t.Errorf("expected an entry larger than the budget to be dropped")
+	}
+	if _, ok := c.Get("small"); !ok {
+		t.Errorf("expected the entries that fit to be kept")
+	}
+	checkBytes(t, c, 6)
+}
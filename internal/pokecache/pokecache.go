@@ -1,82 +1,226 @@
 package pokecache
 
 import (
+	"container/list"
 	"sync"
 	"time"
 )
 
-// CacheEntry represents a single entry in the cache, containing the creation time and the value.
-type CacheEntry struct {
-	CreatedAt time.Time
-	Val       []byte
+// Limits bounds the size of a Cache, once either budget is exceeded the least recently
+// used entries are evicted. A zero value means no limit.
+type Limits struct {
+	MaxBytes   int // total size of the keys and entries held
+	MaxEntries int // number of entries held
 }
 
-// Cache is a simple in-memory cache that stores entries with a key and a value.
+// Cache is a simple in-memory Store that keeps entries in a map, once started entries
+// past their TTL are reaped in the background and the least recently used entries are
+// evicted when the cache grows past its limits.
 type Cache struct {
-	Entries  map[string]CacheEntry
-	MU       sync.Mutex
-	Interval time.Duration
+	entries   map[string]*list.Element // values are *item
+	lru       *list.List               // most recently used at the front
+	bytes     int
+	limits    Limits
+	stats     Stats // hit, miss, eviction and expiration counters
+	mu        sync.Mutex
+	interval  time.Duration
+	now       func() time.Time // the clock entries are created and reaped by, replaced in tests
+	done      chan struct{}
+	reaper    sync.WaitGroup
+	startOnce sync.Once
+	closeOnce sync.Once
 }
 
-// NewCache initializes a new Cache with a specified interval for reaping old entries.
+// item is an entry of the lru list
+type item struct {
+	key   string
+	entry CacheEntry
+	size  int
+}
+
+// NewCache initializes a new unbounded Cache with a specified interval for reaping old
+// entries, the interval is also the TTL of entries added without one. Nothing is reaped
+// until Start is called.
 func NewCache(interval time.Duration) *Cache {
-	c := Cache{}
-	c.Entries = make(map[string]CacheEntry)
-	c.Interval = interval
-	ticker := time.NewTicker(interval)
-	done := make(chan bool)
-
-	go func() {
-		for {
-			select {
-			case <-done:
-				return
-			case <-ticker.C:
-				c.ReapLoop()
+	return NewBoundedCache(interval, Limits{})
+}
+
+// NewBoundedCache initializes a new Cache like NewCache that evicts the least recently
+// used entries to stay within limits.
+func NewBoundedCache(interval time.Duration, limits Limits) *Cache {
+	return &Cache{
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		limits:   limits,
+		interval: interval,
+		now:      time.Now,
+		done:     make(chan struct{}),
+	}
+}
+
+// WithClock replaces the clock of the cache, used to test expiry without waiting
+func (c *Cache) WithClock(now func() time.Time) *Cache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	return c
+}
+
+// Start reaps the cache every interval in the background until Close is called,
+// starting an already started cache does nothing
+func (c *Cache) Start() {
+	c.startOnce.Do(func() {
+		ticker := time.NewTicker(c.interval)
+		c.reaper.Add(1)
+		go func() {
+			defer c.reaper.Done()
+			defer ticker.Stop()
+			for {
+				select {
+				case <-c.done:
+					return
+				case <-ticker.C:
+					c.ReapLoop()
+				}
 			}
-		}
-	}()
-	time.Sleep(1000 * time.Millisecond)
-	return &c
+		}()
+	})
 }
 
-// Add adds a new entry to the cache with the current time as the creation time.
-// If the key already exists, it will overwrite the existing entry.
-func (c *Cache) Add(s string, v []byte) {
-	c.MU.Lock()
+// Add adds an entry to the cache, if the key already exists it will overwrite the existing entry.
+// The entry becomes the most recently used one, an entry larger than the byte budget is not kept.
+func (c *Cache) Add(key string, e CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	defer c.MU.Unlock()
-	ce := CacheEntry{
-		CreatedAt: time.Now(),
-		Val:       v,
+	it := &item{key: key, entry: stamp(e, c.interval, c.now()), size: entrySize(key, e)}
+
+	// an entry that can never fit would flush everything else, drop it and any older value instead
+	if c.limits.MaxBytes > 0 && it.size > c.limits.MaxBytes {
+		if el, ok := c.entries[key]; ok {
+			c.remove(el)
+		}
+		return nil
 	}
-	c.Entries[s] = ce
+
+	if el, ok := c.entries[key]; ok {
+		c.bytes -= el.Value.(*item).size
+		el.Value = it
+		c.lru.MoveToFront(el)
+	} else {
+		c.entries[key] = c.lru.PushFront(it)
+	}
+	c.bytes += it.size
+
+	c.evict()
+	return nil
 }
 
-// Get returns our cache entry if found
-func (c *Cache) Get(s string) ([]byte, bool) {
-	c.MU.Lock()
+// Get returns the cache entry if found, stale or not, and marks it as recently used
+func (c *Cache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return CacheEntry{}, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(el)
+	return el.Value.(*item).entry, true
+}
 
-	defer c.MU.Unlock()
-	_, ok := c.Entries[s]
+// Peek returns the cache entry if found without counting the lookup or marking it as used
+func (c *Cache) Peek(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
 	if !ok {
-		return []byte{}, false
+		return CacheEntry{}, false
 	}
+	return el.Value.(*item).entry, true
+}
 
-	return c.Entries[s].Val, true
+// Delete removes the entry for the key
+func (c *Cache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	return nil
 }
 
-// ReapLoop checks each entry in the cache and removes those that are older than the specified interval.
-// It is called periodically based on the interval set during cache initialization.
+// Range calls fn for each entry in the cache until fn returns false. It works on a
+// copy of the entries so fn is free to call back into the cache.
+func (c *Cache) Range(fn func(key string, e CacheEntry) bool) error {
+	c.mu.Lock()
+	entries := make(map[string]CacheEntry, len(c.entries))
+	for k, el := range c.entries {
+		entries[k] = el.Value.(*item).entry
+	}
+	c.mu.Unlock()
+
+	for k, v := range entries {
+		if !fn(k, v) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Close stops reaping the cache and waits for the reaper to return
+func (c *Cache) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	c.reaper.Wait()
+	return nil
+}
+
+// ReapLoop checks each entry in the cache and removes those that are older than their TTL.
+// Entries with validators are kept so they can be revalidated rather than downloaded again,
+// they only leave the cache when it runs out of room.
+// Once started it is called periodically based on the interval set during cache initialization.
 func (c *Cache) ReapLoop() {
-	c.MU.Lock()
-	defer c.MU.Unlock()
-
-	// if now is after the creation date adding the interval delete the entry
-	now := time.Now()
-	for k, v := range c.Entries {
-		if now.After(v.CreatedAt.Add(c.Interval)) {
-			delete(c.Entries, k)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// if now is after the creation date adding the ttl delete the entry
+	now := c.now()
+	for _, el := range c.entries {
+		e := el.Value.(*item).entry
+		if !e.IsFresh(now) && !e.HasValidators() {
+			c.remove(el)
+			c.stats.Expirations++
 		}
 	}
 }
+
+// evict removes the least recently used entries until the cache is within its limits,
+// the caller holds the lock
+func (c *Cache) evict() {
+	for c.lru.Len() > 0 && c.overLimits() {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// overLimits reports if the cache holds more than its limits allow
+func (c *Cache) overLimits() bool {
+	if c.limits.MaxBytes > 0 && c.bytes > c.limits.MaxBytes {
+		return true
+	}
+	return c.limits.MaxEntries > 0 && c.lru.Len() > c.limits.MaxEntries
+}
+
+// remove drops an element from the map, the lru list and the byte count, the caller holds the lock
+func (c *Cache) remove(el *list.Element) {
+	it := el.Value.(*item)
+	c.lru.Remove(el)
+	delete(c.entries, it.key)
+	c.bytes -= it.size
+}
+
+// entrySize is the number of bytes an entry accounts for in the budget
+func entrySize(key string, e CacheEntry) int {
+	return len(key) + len(e.Val) + len(e.ETag) + len(e.LastModified)
+}
@@ -9,10 +9,10 @@ import (
 func TestCaching(t *testing.T) {
 	c := NewCache(time.Millisecond * 5000)
 
-	c.Add("test1", []byte("hello world1"))
-	c.Add("test2", []byte("hello world"))
-	c.Add("test3", []byte("hello world"))
-	c.Add("test4", []byte("hello world"))
+	c.Add("test1", CacheEntry{Val: []byte("hello world1")})
+	c.Add("test2", CacheEntry{Val: []byte("hello world")})
+	c.Add("test3", CacheEntry{Val: []byte("hello world")})
+	c.Add("test4", CacheEntry{Val: []byte("hello world")})
 
 	want := []byte("hello world1")
 	got, found := c.Get("test1")
@@ -21,8 +21,8 @@ func TestCaching(t *testing.T) {
 		t.Errorf("entry not found in cache")
 	}
 
-	if string(got) != string(want) {
-		t.Errorf("wanted %v got %v", got, want)
+	if string(got.Val) != string(want) {
+		t.Errorf("wanted %v got %v", got.Val, want)
 	}
 
 }
@@ -47,38 +47,143 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
-			cache.Add(c.key, c.val)
-			val, ok := cache.Get(c.key)
+			cache.Add(c.key, CacheEntry{Val: c.val})
+			e, ok := cache.Get(c.key)
 			if !ok {
 				t.Errorf("expected to find key")
 				return
 			}
-			if string(val) != string(c.val) {
+			if string(e.Val) != string(c.val) {
 				t.Errorf("expected to find value")
 				return
 			}
+			if e.TTL != interval {
+				t.Errorf("expected the interval as default ttl, got %v", e.TTL)
+			}
 		})
 	}
 }
 
+func TestDelete(t *testing.T) {
+	cache := NewCache(time.Minute)
+	defer cache.Close()
+
+	cache.Add("https://example.com", CacheEntry{Val: []byte("testdata")})
+	cache.Delete("https://example.com")
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected to NOT find key")
+	}
+}
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+}
+
 func TestReapLoop(t *testing.T) {
 	const baseTime = 5 * time.Millisecond
-	const waitTime = baseTime + 5*time.Millisecond
 
-	cache := NewCache(baseTime)
+	clock := newFakeClock()
+	cache := NewCache(baseTime).WithClock(clock.Now)
 
-	cache.Add("https://example.com", []byte("testdata"))
+	cache.Add("https://example.com", CacheEntry{Val: []byte("testdata")})
 
+	// still inside the ttl
+	clock.Advance(baseTime - time.Millisecond)
+	cache.ReapLoop()
 	_, ok := cache.Get("https://example.com")
 	if !ok {
 		t.Errorf("expected to find key")
 	}
 
-	time.Sleep(waitTime)
-
+	clock.Advance(time.Millisecond)
+	cache.ReapLoop()
 	_, ok = cache.Get("https://example.com")
 	if ok {
 		t.Errorf("expected to NOT find key")
 		return
 	}
 }
+
+func TestReapLoopPerEntryTTL(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(time.Minute).WithClock(clock.Now)
+
+	cache.Add("short", CacheEntry{Val: []byte("testdata"), TTL: time.Second})
+	cache.Add("default", CacheEntry{Val: []byte("testdata")})
+
+	clock.Advance(2 * time.Second)
+	cache.ReapLoop()
+
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("expected the entry with a short ttl to be reaped")
+	}
+	if _, ok := cache.Get("default"); !ok {
+		t.Errorf("expected the entry with the default ttl to be kept")
+	}
+}
+
+func TestReapLoopKeepsValidatedEntries(t *testing.T) {
+	const baseTime = 5 * time.Millisecond
+
+	clock := newFakeClock()
+	cache := NewCache(baseTime).WithClock(clock.Now)
+
+	cache.Add("https://example.com/plain", CacheEntry{Val: []byte("testdata")})
+	cache.Add("https://example.com/etag", CacheEntry{Val: []byte("testdata"), ETag: `"v1"`})
+
+	clock.Advance(2 * baseTime)
+	cache.ReapLoop()
+
+	if _, ok := cache.Get("https://example.com/plain"); ok {
+		t.Errorf("expected to NOT find entry without validators")
+	}
+
+	e, ok := cache.Get("https://example.com/etag")
+	if !ok {
+		t.Fatalf("expected to find entry with validators")
+	}
+	if e.IsFresh(clock.Now()) {
+		t.Errorf("expected entry to be stale")
+	}
+
+	// a revalidated entry is added again with a new creation time
+	e.CreatedAt = clock.Now()
+	cache.Add("https://example.com/etag", e)
+	e, _ = cache.Get("https://example.com/etag")
+	if !e.IsFresh(clock.Now()) {
+		t.Errorf("expected entry to be fresh after revalidation")
+	}
+}
+
+func TestStartClose(t *testing.T) {
+	cache := NewCache(time.Millisecond)
+	cache.Start()
+	cache.Start()
+
+	// close waits for the reaper, a reaper that never stops would hang the test
+	if err := cache.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatalf("unexpected error closing twice: %v", err)
+	}
+
+	// a closed cache is still usable, it is only no longer reaped
+	cache.Add("key", CacheEntry{Val: []byte("val")})
+	if _, ok := cache.Get("key"); !ok {
+		t.Errorf("expected to find key")
+	}
+}
@@ -0,0 +1,106 @@
+package pokecache
+
+import (
+	"context"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/database"
+)
+
+// postgresTimeout bounds every query so a slow database can not stall a command,
+// a query that times out is treated like any other failed cache read or write
+const postgresTimeout = 5 * time.Second
+
+// CacheQuerier is the part of database.Querier the postgres store uses
+type CacheQuerier interface {
+	DeleteCacheEntry(ctx context.Context, key string) error
+	GetCacheEntry(ctx context.Context, key string) (database.CacheEntry, error)
+	ListCacheEntries(ctx context.Context) ([]database.CacheEntry, error)
+	UpsertCacheEntry(ctx context.Context, arg database.UpsertCacheEntryParams) error
+}
+
+// Postgres is a Store keeping entries in the cache_entries table, so machines sharing
+// the database share the cache as well
+type Postgres struct {
+	q   CacheQuerier
+	ttl time.Duration
+}
+
+// NewPostgres returns a postgres store, entries added without a TTL get ttl
+func NewPostgres(q CacheQuerier, ttl time.Duration) *Postgres {
+	return &Postgres{q: q, ttl: ttl}
+}
+
+// Get returns the entry stored for the key, a failed query is reported as a miss
+func (p *Postgres) Get(key string) (CacheEntry, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+
+	row, err := p.q.GetCacheEntry(ctx, key)
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	return postgresEntry(row), true
+}
+
+// Peek is Get, reading a row has no side effects
+func (p *Postgres) Peek(key string) (CacheEntry, bool) {
+	return p.Get(key)
+}
+
+// Add upserts the entry for the key
+func (p *Postgres) Add(key string, e CacheEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+
+	e = stamp(e, p.ttl, time.Now())
+	return p.q.UpsertCacheEntry(ctx, database.UpsertCacheEntryParams{
+		Key:          key,
+		Val:          e.Val,
+		Etag:         e.ETag,
+		LastModified: e.LastModified,
+		CreatedAt:    e.CreatedAt.UTC(),
+		TtlMs:        e.TTL.Milliseconds(),
+	})
+}
+
+// Delete removes the entry for the key
+func (p *Postgres) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+
+	return p.q.DeleteCacheEntry(ctx, key)
+}
+
+// Range calls fn for each entry in the table until fn returns false
+func (p *Postgres) Range(fn func(key string, e CacheEntry) bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+
+	rows, err := p.q.ListCacheEntries(ctx)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if !fn(row.Key, postgresEntry(row)) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Close does nothing, the database connection is owned by the caller
+func (p *Postgres) Close() error {
+	return nil
+}
+
+// postgresEntry converts a table row into a cache entry
+func postgresEntry(row database.CacheEntry) CacheEntry {
+	return CacheEntry{
+		CreatedAt:    row.CreatedAt,
+		TTL:          time.Duration(row.TtlMs) * time.Millisecond,
+		Val:          row.Val,
+		ETag:         row.Etag,
+		LastModified: row.LastModified,
+	}
+}
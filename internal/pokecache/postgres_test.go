@@ -0,0 +1,86 @@
+package pokecache
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/database"
+)
+
+// fakeQueries is an in memory cache_entries table
+type fakeQueries struct {
+	rows map[string]database.CacheEntry
+}
+
+func (f *fakeQueries) DeleteCacheEntry(ctx context.Context, key string) error {
+	delete(f.rows, key)
+	return nil
+}
+
+func (f *fakeQueries) GetCacheEntry(ctx context.Context, key string) (database.CacheEntry, error) {
+	row, ok := f.rows[key]
+	if !ok {
+		return row, sql.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeQueries) ListCacheEntries(ctx context.Context) ([]database.CacheEntry, error) {
+	var rows []database.CacheEntry
+	for _, row := range f.rows {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (f *fakeQueries) UpsertCacheEntry(ctx context.Context, arg database.UpsertCacheEntryParams) error {
+	f.rows[arg.Key] = database.CacheEntry{
+		Key:          arg.Key,
+		Val:          arg.Val,
+		Etag:         arg.Etag,
+		LastModified: arg.LastModified,
+		CreatedAt:    arg.CreatedAt,
+		TtlMs:        arg.TtlMs,
+	}
+	return nil
+}
+
+func TestPostgresStore(t *testing.T) {
+	q := &fakeQueries{rows: map[string]database.CacheEntry{}}
+	store := NewPostgres(q, time.Hour)
+
+	if err := store.Add("https://example.com/pokemon/1/", CacheEntry{Val: []byte("bulbasaur"), LastModified: "yesterday"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row := q.rows["https://example.com/pokemon/1/"]; row.TtlMs != time.Hour.Milliseconds() || row.CreatedAt.IsZero() {
+		t.Errorf("expected the row to carry its ttl and creation time, got %+v", row)
+	}
+
+	e, ok := store.Get("https://example.com/pokemon/1/")
+	if !ok {
+		t.Fatalf("expected to find entry")
+	}
+	if string(e.Val) != "bulbasaur" || e.LastModified != "yesterday" || e.TTL != time.Hour || !e.IsFresh(time.Now()) {
+		t.Errorf("got %+v", e)
+	}
+
+	if _, ok := store.Get("https://example.com/pokemon/2/"); ok {
+		t.Errorf("expected a missing row to be a miss")
+	}
+
+	var keys []string
+	store.Range(func(key string, e CacheEntry) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 1 {
+		t.Errorf("got keys %v", keys)
+	}
+
+	store.Delete("https://example.com/pokemon/1/")
+	if _, ok := store.Get("https://example.com/pokemon/1/"); ok {
+		t.Errorf("expected entry to be deleted")
+	}
+}
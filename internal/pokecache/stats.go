@@ -0,0 +1,79 @@
+package pokecache
+
+import (
+	"strings"
+)
+
+// Stats reports how effective a Cache is
+type Stats struct {
+	Hits        int            // lookups that found an entry
+	Misses      int            // lookups that found nothing
+	Evictions   int            // entries removed to stay within the limits
+	Expirations int            // entries reaped after their TTL
+	Entries     int            // entries held
+	Bytes       int            // size of the entries held, counted the way Limits.MaxBytes is
+	Prefixes    map[string]int // entries held per resource, see Prefix
+}
+
+// StatsReporter is implemented by stores that keep Stats, root is the api root the
+// per resource counts are relative to
+type StatsReporter interface {
+	Stats(root string) Stats
+}
+
+// Stats returns the counters of the cache along with what it holds right now
+func (c *Cache) Stats(root string) Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.stats
+	s.Entries = c.lru.Len()
+	s.Bytes = c.bytes
+	s.Prefixes = map[string]int{}
+	for key := range c.entries {
+		s.Prefixes[Prefix(root, key)]++
+	}
+	return s
+}
+
+// Stats returns the hits and misses of both tiers together and what they hold, an entry
+// in both tiers is counted once. Evictions and expirations only happen in the front.
+func (t *Tiered) Stats(root string) Stats {
+	var s Stats
+	if r, ok := t.front.(StatsReporter); ok {
+		s = r.Stats(root)
+	}
+
+	t.mu.Lock()
+	s.Hits, s.Misses = t.hits, t.misses
+	t.mu.Unlock()
+
+	var held Stats
+	held.Prefixes = map[string]int{}
+	err := t.Range(func(key string, e CacheEntry) bool {
+		held.Entries++
+		held.Bytes += entrySize(key, e)
+		held.Prefixes[Prefix(root, key)]++
+		return true
+	})
+	// when the back can not be listed the front is all that is known
+	if err == nil {
+		s.Entries, s.Bytes, s.Prefixes = held.Entries, held.Bytes, held.Prefixes
+	}
+	return s
+}
+
+// Prefix returns the resource a key belongs to, e.g. pokemon for <root>pokemon/pikachu/.
+// Keys outside of root are grouped by their host.
+func Prefix(root, key string) string {
+	rel, ok := strings.CutPrefix(key, root)
+	if !ok {
+		rel = key
+		if _, after, found := strings.Cut(key, "://"); found {
+			rel = after
+		}
+	}
+	resource, _, _ := strings.Cut(rel, "/")
+	resource, _, _ = strings.Cut(resource, "?")
+	return resource
+}
@@ -0,0 +1,127 @@
+package pokecache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	const root = "https://pokeapi.co/api/v2/"
+	c := NewBoundedCache(time.Hour, Limits{MaxEntries: 3})
+	defer c.Close()
+
+	c.Add(root+"pokemon/pikachu/", CacheEntry{Val: []byte("pikachu")})
+	c.Add(root+"pokemon/eevee/", CacheEntry{Val: []byte("eevee")})
+	c.Add(root+"location-area/?offset=0&limit=20", CacheEntry{Val: []byte("page")})
+	c.Add(root+"location-area/canalave-city-area/", CacheEntry{Val: []byte("area")}) // evicts pikachu
+	c.Get(root + "pokemon/eevee/")
+	c.Add("https://example.com/other", CacheEntry{Val: []byte("x"), CreatedAt: time.Now().Add(-2 * time.Hour)}) // evicts the page
+
+	c.Get(root + "pokemon/pikachu/")
+	c.Get(root + "pokemon/mew/")
+	c.ReapLoop()
+
+	s := c.Stats(root)
+	if s.Hits != 1 || s.Misses != 2 || s.Evictions != 2 || s.Expirations != 1 {
+		t.Errorf("got %d hits, %d misses, %d evictions and %d expirations want 1, 2, 2 and 1", s.Hits, s.Misses, s.Evictions, s.Expirations)
+	}
+	if s.Entries != 2 {
+		t.Errorf("got %d entries want 2", s.Entries)
+	}
+	if s.Prefixes["pokemon"] != 1 || s.Prefixes["location-area"] != 1 || len(s.Prefixes) != 2 {
+		t.Errorf("got prefixes %v", s.Prefixes)
+	}
+	checkBytes(t, c, s.Bytes)
+}
+
+func TestPrefix(t *testing.T) {
+	const root = "https://pokeapi.co/api/v2/"
+	cases := []struct {
+		key    string
+		expect string
+	}{
+		{key: root + "pokemon/pikachu/", expect: "pokemon"},
+		{key: root + "location-area/?offset=0&limit=20", expect: "location-area"},
+		{key: root + "pokemon-species?limit=1", expect: "pokemon-species"},
+		{key: "https://example.com/other", expect: "example.com"},
+	}
+
+	for _, c := range cases {
+		if got := Prefix(root, c.key); got != c.expect {
+			t.Errorf("%s: got %s want %s", c.key, got, c.expect)
+		}
+	}
+}
+
+func TestTieredStats(t *testing.T) {
+	const root = "https://pokeapi.co/api/v2/"
+	disk, err := NewDisk(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.Add(root+"pokemon/pikachu/", CacheEntry{Val: []byte("pikachu")})
+	disk.Add(root+"pokemon/eevee/", CacheEntry{Val: []byte("eevee")})
+
+	front := NewCache(time.Hour)
+	c := NewTiered(front, disk)
+	defer c.Close()
+	c.Add(root+"location-area/canalave-city-area/", CacheEntry{Val: []byte("area")})
+
+	c.Get(root + "pokemon/pikachu/") // served by the disk
+	c.Get(root + "pokemon/pikachu/") // served by the memory
+	c.Get(root + "location-area/canalave-city-area/")
+	c.Get(root + "pokemon/mew/")
+
+	s := c.Stats(root)
+	if s.Hits != 3 || s.Misses != 1 {
+		t.Errorf("got %d hits and %d misses want 3 and 1", s.Hits, s.Misses)
+	}
+	if s.Entries != 3 || s.Prefixes["pokemon"] != 2 || s.Prefixes["location-area"] != 1 {
+		t.Errorf("got %d entries and prefixes %v want the 3 entries of both tiers", s.Entries, s.Prefixes)
+	}
+	want := len(root+"pokemon/pikachu/pikachu") + len(root+"pokemon/eevee/eevee") + len(root+"location-area/canalave-city-area/area")
+	if s.Bytes != want {
+		t.Errorf("got %d bytes want %d", s.Bytes, want)
+	}
+}
+
+func TestPeekHasNoSideEffects(t *testing.T) {
+	c := NewBoundedCache(time.Hour, Limits{MaxEntries: 2})
+	defer c.Close()
+
+	c.Add("a", CacheEntry{Val: []byte("a")})
+	c.Add("b", CacheEntry{Val: []byte("b")})
+	if e, ok := c.Peek("a"); !ok || string(e.Val) != "a" {
+		t.Errorf("got %+v, %t want a", e, ok)
+	}
+	if _, ok := c.Peek("missing"); ok {
+		t.Errorf("expected a missing key not to be found")
+	}
+
+	// a was not marked as used, so it is still the first to go
+	c.Add("c", CacheEntry{Val: []byte("c")})
+	if _, ok := c.Peek("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+
+	s := c.Stats("")
+	if s.Hits != 0 || s.Misses != 0 {
+		t.Errorf("got %d hits and %d misses want none", s.Hits, s.Misses)
+	}
+
+	disk, err := NewDisk(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.Add("d", CacheEntry{Val: []byte("d")})
+	tiered := NewTiered(NewCache(time.Hour), disk)
+	if _, ok := tiered.Peek("d"); !ok {
+		t.Errorf("expected to find d on disk")
+	}
+	if _, ok := tiered.front.Peek("d"); ok {
+		t.Errorf("expected peek not to copy d into the front")
+	}
+	if s := tiered.Stats(""); s.Hits != 0 || s.Misses != 0 {
+		t.Errorf("got %d hits and %d misses want none", s.Hits, s.Misses)
+	}
+}
@@ -0,0 +1,63 @@
+package pokecache
+
+import "time"
+
+// CacheEntry represents a single entry in the cache, containing the creation time and the value.
+// TTL is how long the entry stays fresh, an entry added with no TTL gets the default of its store.
+// ETag and LastModified hold the http validators the value was served with, an entry
+// with validators can be revalidated once it is stale instead of being downloaded again.
+type CacheEntry struct {
+	CreatedAt    time.Time
+	TTL          time.Duration
+	Val          []byte
+	ETag         string
+	LastModified string
+}
+
+// HasValidators reports if the entry can be revalidated with a conditional request
+func (e CacheEntry) HasValidators() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// IsFresh reports if the entry is younger than its TTL at now and can be used
+// without asking the server
+func (e CacheEntry) IsFresh(now time.Time) bool {
+	return now.Sub(e.CreatedAt) < e.TTL
+}
+
+// Store is a cache backend keyed by url. Get returns stale entries too so the caller
+// can revalidate them, a store that fails to read reports a miss.
+type Store interface {
+	// Get returns the entry stored for the key
+	Get(key string) (CacheEntry, bool)
+	// Peek returns the entry stored for the key like Get, but is not counted in the
+	// stats and does not change which entries are evicted first
+	Peek(key string) (CacheEntry, bool)
+	// Add stores the entry for the key, overwriting any existing one. An entry without
+	// a creation time is created now and one without a TTL gets the store default.
+	Add(key string, e CacheEntry) error
+	// Delete removes the entry for the key, deleting a missing key is not an error
+	Delete(key string) error
+	// Range calls fn for each entry until fn returns false, fn is free to call back into the store
+	Range(fn func(key string, e CacheEntry) bool) error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+var (
+	_ Store = (*Cache)(nil)
+	_ Store = (*Disk)(nil)
+	_ Store = (*Postgres)(nil)
+	_ Store = (*Tiered)(nil)
+)
+
+// stamp fills in the creation time and the TTL of an entry being added at now
+func stamp(e CacheEntry, ttl time.Duration, now time.Time) CacheEntry {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	if e.TTL <= 0 {
+		e.TTL = ttl
+	}
+	return e
+}
@@ -0,0 +1,119 @@
+package pokecache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Tiered is a Store that keeps a fast front store, usually memory, in front of a persistent
+// back store such as Disk or Postgres. Entries are written to both and entries missing
+// from the front are read from the back and kept in the front.
+type Tiered struct {
+	front  Store
+	back   Store
+	now    func() time.Time // the clock entries are created and expired by, replaced in tests
+	mu     sync.Mutex
+	hits   int // lookups served by either tier
+	misses int // lookups neither tier could serve
+}
+
+// NewTiered returns a store reading through front to back
+func NewTiered(front, back Store) *Tiered {
+	return &Tiered{front: front, back: back, now: time.Now}
+}
+
+// WithClock replaces the clock of the store, used to test expiry without waiting. The
+// tiers keep their own clocks. It must be called before the store is used.
+func (t *Tiered) WithClock(now func() time.Time) *Tiered {
+	t.now = now
+	return t
+}
+
+// Get returns the entry from the front, or from the back when the front does not have it.
+// An entry from the back that expired and can not be revalidated is removed instead.
+func (t *Tiered) Get(key string) (CacheEntry, bool) {
+	e, ok := t.get(key)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ok {
+		t.hits++
+	} else {
+		t.misses++
+	}
+	return e, ok
+}
+
+// get looks the key up in the front and then in the back
+func (t *Tiered) get(key string) (CacheEntry, bool) {
+	if e, ok := t.front.Get(key); ok {
+		return e, true
+	}
+
+	e, ok := t.back.Get(key)
+	if !ok {
+		return e, false
+	}
+	if !e.IsFresh(t.now()) && !e.HasValidators() {
+		_ = t.back.Delete(key)
+		return CacheEntry{}, false
+	}
+
+	// the back is best effort, keeping the entry in the front only saves a read
+	_ = t.front.Add(key, e)
+	return e, true
+}
+
+// Peek returns the entry from the front, or from the back when the front does not have it,
+// without counting the lookup or copying the entry into the front
+func (t *Tiered) Peek(key string) (CacheEntry, bool) {
+	if e, ok := t.front.Peek(key); ok {
+		return e, true
+	}
+	return t.back.Peek(key)
+}
+
+// Add adds the entry to both tiers with the same creation time
+func (t *Tiered) Add(key string, e CacheEntry) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = t.now()
+	}
+	return errors.Join(t.front.Add(key, e), t.back.Add(key, e))
+}
+
+// Delete removes the entry from both tiers
+func (t *Tiered) Delete(key string) error {
+	return errors.Join(t.front.Delete(key), t.back.Delete(key))
+}
+
+// Range calls fn for each entry of either tier until fn returns false, the front
+// entry wins when both tiers hold the key
+func (t *Tiered) Range(fn func(key string, e CacheEntry) bool) error {
+	entries := map[string]CacheEntry{}
+	err := t.back.Range(func(key string, e CacheEntry) bool {
+		entries[key] = e
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	err = t.front.Range(func(key string, e CacheEntry) bool {
+		entries[key] = e
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range entries {
+		if !fn(k, v) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Close closes both tiers
+func (t *Tiered) Close() error {
+	return errors.Join(t.front.Close(), t.back.Close())
+}
@@ -0,0 +1,91 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/joshhartwig/pokedex/internal/api"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// Ability displays the effect of an ability and the pokemon that can have it.
+func Ability(ctx context.Context, c *models.Config, args ...string) error {
+	if err := checkArgs(2, args); err != nil {
+		return errors.New("invalid ability")
+	}
+	name := args[1]
+
+	ability, err := c.Client.GetAbility(ctx, name)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such ability: %s\n", name)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching ability", "ability", name, "error", err)
+		return err
+	}
+
+	fmt.Printf("Name: %s\n", ability.Name)
+	fmt.Printf("Generation: %s\n", orUnknown(ability.Generation.Name))
+	if effect := englishShortEffect(ability.EffectEntries); effect != "" {
+		fmt.Printf("Effect: %s\n", effect)
+	}
+	if len(ability.Pokemon) > 0 {
+		fmt.Println("Pokemon:")
+		for _, p := range ability.Pokemon {
+			fmt.Printf("  - %s\n", hiddenLabel(p.Pokemon.Name, p.IsHidden))
+		}
+	}
+	return nil
+}
+
+// Item displays the details of an item and the wild pokemon that can hold it.
+func Item(ctx context.Context, c *models.Config, args ...string) error {
+	if err := checkArgs(2, args); err != nil {
+		return errors.New("invalid item")
+	}
+	name := args[1]
+
+	item, err := c.Client.GetItem(ctx, name)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such item: %s\n", name)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching item", "item", name, "error", err)
+		return err
+	}
+
+	fmt.Printf("Name: %s\n", item.Name)
+	fmt.Printf("Category: %s\n", orUnknown(item.Category.Name))
+	fmt.Printf("Cost: %d\n", item.Cost)
+	if effect := englishShortEffect(item.EffectEntries); effect != "" {
+		fmt.Printf("Effect: %s\n", effect)
+	}
+	if len(item.HeldByPokemon) > 0 {
+		fmt.Println("Held By:")
+		for _, p := range item.HeldByPokemon {
+			fmt.Printf("  - %s: %s\n", p.Pokemon.Name, rarities(p.VersionDetails))
+		}
+	}
+	return nil
+}
+
+// hiddenLabel returns the name of an ability, or of a pokemon having it, marked when it is hidden
+func hiddenLabel(name string, hidden bool) string {
+	if hidden {
+		return name + " (hidden)"
+	}
+	return name
+}
+
+// rarities formats the chance of holding an item per version, e.g. red 50%, blue 50%
+func rarities(details []models.VersionRarity) string {
+	parts := make([]string, len(details))
+	for i, d := range details {
+		parts[i] = fmt.Sprintf("%s %d%%", d.Version.Name, d.Rarity)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,135 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// Cache inspects and invalidates the response cache:
+//
+//	cache stats            hits, misses, evictions, expirations, size and entries per resource
+//	cache list [prefix]    the cached urls, relative to the api root, starting with prefix
+//	cache clear [prefix]   removes the cached urls starting with prefix, or everything
+func Cache(ctx context.Context, c *models.Config, args ...string) error {
+	if len(args) < 2 {
+		return errors.New("usage: cache stats|list [prefix]|clear [prefix]")
+	}
+	prefix := ""
+	if len(args) > 2 {
+		prefix = args[2]
+	}
+
+	switch args[1] {
+	case "stats":
+		return cacheStats(c)
+	case "list":
+		return cacheList(c, prefix)
+	case "clear":
+		return cacheClear(c, prefix)
+	default:
+		return fmt.Errorf("unknown cache command %q, use stats, list or clear", args[1])
+	}
+}
+
+// cacheStats prints the statistics of the cache
+func cacheStats(c *models.Config) error {
+	r, ok := c.Cache.(pokecache.StatsReporter)
+	if !ok {
+		fmt.Println("this cache does not keep statistics")
+		return nil
+	}
+	s := r.Stats(c.ApiRoot)
+
+	hitRate := 0.0
+	if lookups := s.Hits + s.Misses; lookups > 0 {
+		hitRate = float64(s.Hits) / float64(lookups) * 100
+	}
+
+	fmt.Println("Cache:")
+	fmt.Printf("  Hits: %d\n", s.Hits)
+	fmt.Printf("  Misses: %d\n", s.Misses)
+	fmt.Printf("  Hit Rate: %.1f%%\n", hitRate)
+	fmt.Printf("  Evictions: %d\n", s.Evictions)
+	fmt.Printf("  Expirations: %d\n", s.Expirations)
+	fmt.Printf("  Entries: %d\n", s.Entries)
+	fmt.Printf("  Bytes: %d\n", s.Bytes)
+
+	prefixes := make([]string, 0, len(s.Prefixes))
+	for p := range s.Prefixes {
+		prefixes = append(prefixes, p)
+	}
+	slices.Sort(prefixes)
+	for _, p := range prefixes {
+		fmt.Printf("  - %s: %d\n", p, s.Prefixes[p])
+	}
+	return nil
+}
+
+// cacheList prints the cached urls starting with prefix, sorted
+func cacheList(c *models.Config, prefix string) error {
+	keys, entries, err := cachedKeys(c, prefix)
+	if err != nil {
+		c.Logger.Error("error listing cache", "error", err)
+		return err
+	}
+	if len(keys) == 0 {
+		fmt.Println("no cached entries")
+		return nil
+	}
+
+	now := time.Now()
+	for _, key := range keys {
+		e := entries[key]
+		state := "fresh"
+		if !e.IsFresh(now) {
+			state = "stale"
+		}
+		fmt.Printf("%s (%d bytes, %s)\n", strings.TrimPrefix(key, c.ApiRoot), len(e.Val), state)
+	}
+	return nil
+}
+
+// cacheClear removes the cached urls starting with prefix
+func cacheClear(c *models.Config, prefix string) error {
+	keys, _, err := cachedKeys(c, prefix)
+	if err != nil {
+		c.Logger.Error("error listing cache", "error", err)
+		return err
+	}
+
+	for _, key := range keys {
+		if err := c.Cache.Delete(key); err != nil {
+			c.Logger.Error("error deleting cache entry", "key", key, "error", err)
+			return err
+		}
+	}
+	fmt.Printf("Removed %d cached entries\n", len(keys))
+	return nil
+}
+
+// cachedKeys returns the sorted keys starting with prefix and their entries. The prefix is
+// relative to the api root, e.g. pokemon/, unless it is a full url.
+func cachedKeys(c *models.Config, prefix string) ([]string, map[string]pokecache.CacheEntry, error) {
+	if prefix != "" && !strings.Contains(prefix, "://") {
+		prefix = c.ApiRoot + prefix
+	}
+
+	var keys []string
+	entries := map[string]pokecache.CacheEntry{}
+	err := c.Cache.Range(func(key string, e pokecache.CacheEntry) bool {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+			entries[key] = e
+		}
+		return true
+	})
+	slices.Sort(keys)
+	return keys, entries, err
+}
@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
-	"os"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -16,15 +16,18 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+// ErrExit is returned by the exit command to end the session, Repl returns when it sees
+// it so the caller can release its resources before quitting
+var ErrExit = errors.New("exit requested")
+
 // Exit is a command that exits the Pokedex application.
-func Exit(c *models.Config, args ...string) error {
+func Exit(ctx context.Context, c *models.Config, args ...string) error {
 	fmt.Println("Exiting Pokedex...")
-	os.Exit(0)
-	return nil
+	return ErrExit
 }
 
 // Help displays the help screen for the Pokedex application.
-func Help(c *models.Config, args ...string) error {
+func Help(ctx context.Context, c *models.Config, args ...string) error {
 	fmt.Println("Welcome to the Pokedex!")
 	fmt.Println("Usage:")
 	fmt.Printf("  %-10s %s\n", "help:", "Displays this help screen")
@@ -33,70 +36,83 @@ func Help(c *models.Config, args ...string) error {
 	fmt.Printf("  %-10s %s\n", "catch", "Attempts to catch a specific pokemon")
 	fmt.Printf("  %-10s %s\n", "explore:", "Displays pokemon in a specific region")
 	fmt.Printf("  %-10s %s\n", "inspect:", "Displays stats for a specific pokemon (must be caught first)")
+	fmt.Printf("  %-10s %s\n", "evolution:", "Displays the evolution chain of a pokemon, caught members are marked")
+	fmt.Printf("  %-10s %s\n", "types:", "Displays the damage a type deals and takes from other types")
+	fmt.Printf("  %-10s %s\n", "matchup:", "Displays the damage multiplier of an attacking type against a pokemon, e.g. matchup ground charizard")
+	fmt.Printf("  %-10s %s\n", "moves:", "Displays the moves a pokemon learns by method, optionally for a version group e.g. moves pikachu red-blue")
+	fmt.Printf("  %-10s %s\n", "move:", "Displays the power, accuracy, pp, type and effect of a move")
+	fmt.Printf("  %-10s %s\n", "ability:", "Displays the effect of an ability and the pokemon that can have it")
+	fmt.Printf("  %-10s %s\n", "item:", "Displays the effect of an item and the wild pokemon that hold it")
+	fmt.Printf("  %-10s %s\n", "where:", "Displays where a pokemon can be encountered, add chance to sort by the most likely e.g. where pikachu chance")
+	fmt.Printf("  %-10s %s\n", "lang:", "Shows names in a language where the api has them e.g. lang ja, lang none shows the slugs again")
+	fmt.Printf("  %-10s %s\n", "prefetch:", "Downloads every location area and pokemon into the cache, optionally with a number of workers")
+	fmt.Printf("  %-10s %s\n", "snapshot:", "Writes everything downloaded so far to a directory for offline use")
+	fmt.Printf("  %-10s %s\n", "cache:", "Inspects the response cache: cache stats, cache list [prefix], cache clear [prefix] e.g. cache clear pokemon/")
 	fmt.Printf("  %-10s %s\n", "exit:", "Exit the Pokedex")
 	return nil
 }
 
-// Map fetches the map of locations from the PokeAPI and displays them.
-func Map(c *models.Config, args ...string) error {
-
-	var ah models.Apiheader
-	// if c.next is anything but empty, it likely has a url and pull from that url
-	if c.Next != "" {
-		if err := api.FetchFromCache(c, c.Next, &ah); err != nil {
-			c.Logger.Error("error fetching from cache", "error", err)
-			return err
-		}
-	} else {
-		// set the previous the base url the 1st time
-		c.Previous = c.BaseApiUrl
-		// fetch and encode from baseapiurl
-		if err := api.FetchFromCache(c, c.BaseApiUrl, &ah); err != nil {
-			c.Logger.Error("error doing fetching from cache", "error", err)
-			return err
-		}
-		// set the next url
-		c.Next = ah.Next
-
+// Map fetches the next page of locations from the PokeAPI and displays them, the first
+// call shows the first page.
+func Map(ctx context.Context, c *models.Config, args ...string) error {
+	page := models.PageCursor{Limit: models.DefaultPageLimit}
+	if c.MapPage != nil {
+		page = c.MapPage.Next()
 	}
 
-	// loop through the results
-	for _, l := range ah.Results {
-		fmt.Println(l.Name)
+	shown, err := showLocationPage(ctx, c, page)
+	if err != nil {
+		return err
+	}
+	if !shown {
+		fmt.Println("you're on the last page")
+		return nil
 	}
 
+	c.MapPage = &page
 	return nil
 }
 
-// Mapb fetches the previous map of locations from the PokeAPI and displays them.
-func Mapb(c *models.Config, args ...string) error {
-	var ah models.Apiheader
-	if c.Previous != "" {
-		if err := api.FetchFromCache(c, c.Previous, &ah); err != nil {
-			c.Logger.Error("error fetching from cache", "error", err)
-			return err
-		}
-	} else {
-		if err := api.FetchFromCache(c, c.BaseApiUrl, &ah); err != nil {
-			c.Logger.Error("error fetching from cache", "error", err)
-			return err
+// Mapb fetches the previous page of locations from the PokeAPI and displays them, if
+// called with no map call it shows the first page.
+func Mapb(ctx context.Context, c *models.Config, args ...string) error {
+	page := models.PageCursor{Limit: models.DefaultPageLimit}
+	if c.MapPage != nil {
+		if c.MapPage.IsFirst() {
+			fmt.Println("you're on the first page")
+			return nil
 		}
+		page = c.MapPage.Prev()
 	}
 
-	// set the next url
-	c.Next = ah.Next
-
-	// loop through the results
-	for _, l := range ah.Results {
-		fmt.Println(l.Name)
+	if _, err := showLocationPage(ctx, c, page); err != nil {
+		return err
 	}
 
+	c.MapPage = &page
 	return nil
+}
 
+// showLocationPage prints the locations on the page, shown is false if the page is empty
+func showLocationPage(ctx context.Context, c *models.Config, page models.PageCursor) (shown bool, err error) {
+	ah, err := c.Client.ListLocationAreas(ctx, page)
+	if err != nil {
+		c.Logger.Error("error listing location areas", "error", err)
+		return false, err
+	}
+
+	// loop through the results
+	for _, l := range ah.Results {
+		fmt.Println(areaName(ctx, c, l.Name))
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return len(ah.Results) > 0, nil
 }
 
 // AltExplore is an alternative explore function that allows for more direct exploration
-func Explore(c *models.Config, args ...string) error {
+func Explore(ctx context.Context, c *models.Config, args ...string) error {
 	// check if args are empty
 	if err := checkArgs(2, args); err != nil {
 		return errors.New("invalid location")
@@ -105,25 +121,26 @@ func Explore(c *models.Config, args ...string) error {
 	// clean and trim them
 	cleanLocation := strings.TrimSpace(strings.ToLower(args[1]))
 
-	// check if previous url is set, if not set to base url
-	if c.Previous == "" {
-		c.Previous = c.BaseApiUrl
-	}
-
 	// download the json data and encode to struct
-	var locationArea models.LocationArea
-	locationUrl := fmt.Sprintf("%s%s", c.Previous, cleanLocation)
-	api.FetchFromCache(c, locationUrl, &locationArea)
+	locationArea, err := c.Client.GetLocationArea(ctx, cleanLocation)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such location: %s\n", cleanLocation)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching location area", "location", cleanLocation, "error", err)
+		return err
+	}
 
 	fmt.Println("Found Pokemon:")
 	for _, k := range locationArea.PokemonEncounters {
-		fmt.Printf("- %s\n", k.Pokemon.Name)
+		fmt.Printf("- %s\n", pokemonName(ctx, c, k.Pokemon.Name))
 	}
-	return nil
+	return ctx.Err()
 }
 
 // Catch attempts to catch a pokemon by throwing a Pokeball at it.
-func Catch(c *models.Config, args ...string) error {
+func Catch(ctx context.Context, c *models.Config, args ...string) error {
 	if err := checkArgs(2, args); err != nil {
 		// log the error and return
 		return errors.New("invalid character")
@@ -138,17 +155,23 @@ func Catch(c *models.Config, args ...string) error {
 	}
 
 	// check the db to see if the char exists
-	_, err := c.Db.GetPokemonByName(context.Background(), character)
+	_, err := c.Db.GetPokemonByName(ctx, character)
 	if err == nil {
 		return fmt.Errorf("already caught %s", character)
 	}
 
-	fmt.Printf("Throwing a Pokeball at %s...\n", character)
-
 	// fetch and encode the pokemon data from the api
-	url := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s/", character)
-	var pokemon models.Pokemon
-	api.FetchFromCache(c, url, &pokemon)
+	pokemon, err := c.Client.GetPokemon(ctx, character)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such pokemon: %s\n", character)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching pokemon", "pokemon", character, "error", err)
+		return err
+	}
+
+	fmt.Printf("Throwing a Pokeball at %s...\n", character)
 
 	// attempt to catch pokemon
 	if CatchPokemon(.25, pokemon.BaseExperience) {
@@ -162,7 +185,7 @@ func Catch(c *models.Config, args ...string) error {
 		}
 
 		//write to database
-		_, err = c.Db.AddPokemon(context.Background(), database.AddPokemonParams{
+		_, err = c.Db.AddPokemon(ctx, database.AddPokemonParams{
 			ID:          uuid.New(),
 			PokemonName: character,
 			JsonData:    pqtype.NullRawMessage{RawMessage: jsonData, Valid: true},
@@ -179,8 +202,11 @@ func Catch(c *models.Config, args ...string) error {
 	return nil
 }
 
-// Inspect displays the details of a caught pokemon.
-func Inspect(c *models.Config, args ...string) error {
+// Inspect displays the details of a caught pokemon along with the pokedex data of its species.
+func Inspect(ctx context.Context, c *models.Config, args ...string) error {
+	if err := checkArgs(2, args); err != nil {
+		return errors.New("invalid character")
+	}
 	character := args[1]
 	val, ok := c.Pokedex[character]
 	if !ok {
@@ -188,7 +214,20 @@ func Inspect(c *models.Config, args ...string) error {
 		return nil
 	}
 
-	fmt.Printf("Name: %s\n", val.Name)
+	// the species holds the pokedex entry and the local names, a pokemon is still worth showing without it
+	speciesName := val.Species.Name
+	if speciesName == "" {
+		speciesName = val.Name
+	}
+	species, err := c.Client.GetPokemonSpecies(ctx, speciesName)
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	if err != nil {
+		c.Logger.Error("error fetching species", "species", speciesName, "error", err)
+	}
+
+	fmt.Printf("Name: %s\n", localName(c, species.Names, val.Name))
 	fmt.Printf("Height: %d\n", val.Height)
 	fmt.Printf("Weight: %d\n", val.Weight)
 	fmt.Println("Stats:")
@@ -199,32 +238,59 @@ func Inspect(c *models.Config, args ...string) error {
 	for _, t := range val.Types {
 		fmt.Printf("  - %s\n", t.Type.Name)
 	}
-	return nil
+	fmt.Println("Abilities:")
+	for _, a := range val.Abilities {
+		fmt.Printf("  - %s\n", hiddenLabel(a.Ability.Name, a.IsHidden))
+	}
+	if len(val.HeldItems) > 0 {
+		fmt.Println("Held Items:")
+		for _, h := range val.HeldItems {
+			fmt.Printf("  - %s: %s\n", h.Item.Name, rarities(h.VersionDetails))
+		}
+	}
+
+	// without the species there is no pokedex entry to show
+	if err != nil {
+		return nil
+	}
 
+	fmt.Println("Species:")
+	if genus := speciesGenus(species, c.Language); genus != "" {
+		fmt.Printf("  Genus: %s\n", genus)
+	}
+	if text := speciesFlavorText(species, c.Language); text != "" {
+		fmt.Printf("  Description: %s\n", text)
+	}
+	fmt.Printf("  Habitat: %s\n", orUnknown(species.Habitat.Name))
+	fmt.Printf("  Growth Rate: %s\n", orUnknown(species.GrowthRate.Name))
+	fmt.Printf("  Capture Rate: %d/255\n", species.CaptureRate)
+	fmt.Printf("  Legendary: %t\n", species.IsLegendary)
+	fmt.Printf("  Mythical: %t\n", species.IsMythical)
+	return nil
 }
 
 // Pokedex displays the list of caught pokemon.
-func Pokedex(c *models.Config, args ...string) error {
+func Pokedex(ctx context.Context, c *models.Config, args ...string) error {
 	fmt.Println("Your Pokedex:")
 	if len(c.Pokedex) == 0 {
 		fmt.Println("You have not caught any pokemon yet.")
 		return nil
 	}
 
-	pokemon, err := c.Db.ListPokemon(context.Background())
+	pokemon, err := c.Db.ListPokemon(ctx)
 	if err != nil {
 		c.Logger.Error("error fetching pokemon from database", "error", err)
 		return err
 	}
 
 	for _, b := range pokemon {
-		fmt.Println(b.PokemonName)
+		fmt.Println(pokemonName(ctx, c, b.PokemonName))
 	}
 	return nil
 }
 
 // History shows all the commands that were previously used
-func History(c *models.Config, args ...string) error {
+func History(ctx context.Context, c *models.Config, args ...string) error {
 	fmt.Println("History:")
 	for _, c := range c.History {
 		fmt.Printf("-%s\n", c)
@@ -246,7 +312,7 @@ func History(c *models.Config, args ...string) error {
 // Returns an error if:
 // - Incorrect number of arguments provided
 // - Either Pokemon is not found in the Pokedex
-func Fight(c *models.Config, args ...string) error {
+func Fight(ctx context.Context, c *models.Config, args ...string) error {
 	err := checkArgs(3, args)
 	if err != nil {
 		return errors.New("not enough arguments, Fight requires 3 arguments")
@@ -296,10 +362,75 @@ func Fight(c *models.Config, args ...string) error {
 		loser = firstChar
 	} else {
 		fmt.Println("It's a tie! Rolling again...")
-		return Fight(c, args...) // rerun fight on tie
+		return Fight(ctx, c, args...) // rerun fight on tie
 	}
 
 	fmt.Printf("%s wins! %s is removed from your pokedex.\n", winner, loser)
 	delete(c.Pokedex, loser)
 	return nil
 }
+
+// Snapshot writes every api response in the cache to the given directory so it can be
+// carried to a machine without internet access and served with POKEDEX_OFFLINE_DIR.
+func Snapshot(ctx context.Context, c *models.Config, args ...string) error {
+	if err := checkArgs(2, args); err != nil {
+		return errors.New("snapshot requires a directory")
+	}
+	dir := args[1]
+
+	endpoints, err := api.NewEndpoints(c.ApiRoot)
+	if err != nil {
+		return err
+	}
+
+	written, err := api.WriteSnapshot(c.Cache, endpoints, dir)
+	if err != nil {
+		c.Logger.Error("error writing snapshot", "dir", dir, "error", err)
+		return err
+	}
+
+	fmt.Printf("Wrote %d files to %s\n", written, dir)
+	return nil
+}
+
+// Prefetch downloads every location area and every pokemon found in them into the cache,
+// e.g. before going offline. Anything fresh in the cache is not downloaded again, so an
+// interrupted prefetch can simply be run again. An optional argument sets the number of
+// workers.
+func Prefetch(ctx context.Context, c *models.Config, args ...string) error {
+	workers := 4
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return errors.New("the number of workers must be a positive number")
+		}
+		workers = n
+	}
+
+	endpoints, err := api.NewEndpoints(c.ApiRoot)
+	if err != nil {
+		return err
+	}
+
+	progress, err := api.Prefetch(ctx, c.Client, api.PrefetchOptions{
+		Workers:   workers,
+		Endpoints: endpoints,
+		Cache:     c.Cache,
+		Progress: func(p api.PrefetchProgress) {
+			if p.Total == 0 {
+				fmt.Printf("\r%-8s %5d      ", p.Stage, p.Done)
+				return
+			}
+			fmt.Printf("\r%-8s %5d/%-5d", p.Stage, p.Done, p.Total)
+		},
+	})
+	fmt.Println()
+	if err != nil {
+		c.Logger.Error("prefetch stopped", "error", err)
+		return err
+	}
+
+	fmt.Printf("Prefetched %d areas and %d pokemon, %d pokemon were already cached and %d failed\n",
+		progress.Areas, progress.Pokemon, progress.Skipped, progress.Failed)
+	return nil
+}
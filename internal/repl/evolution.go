@@ -0,0 +1,174 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joshhartwig/pokedex/internal/api"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// Evolution displays the full evolution chain of a pokemon as a tree, with the trigger and
+// conditions of every evolution. Members of the chain that are in the pokedex are marked.
+func Evolution(ctx context.Context, c *models.Config, args ...string) error {
+	if err := checkArgs(2, args); err != nil {
+		return errors.New("invalid character")
+	}
+	character := args[1]
+
+	species, err := findSpecies(ctx, c, character)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such pokemon: %s\n", character)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching species", "pokemon", character, "error", err)
+		return err
+	}
+
+	if species.EvolutionChain.URL == "" {
+		fmt.Printf("%s does not evolve\n", species.Name)
+		return nil
+	}
+
+	var chain models.EvolutionChain
+	if err := c.Client.Fetch(ctx, species.EvolutionChain.URL, &chain); err != nil {
+		c.Logger.Error("error fetching evolution chain", "url", species.EvolutionChain.URL, "error", err)
+		return err
+	}
+
+	fmt.Printf("Evolution chain of %s:\n", species.Name)
+	fmt.Print(renderChain(chain.Chain, caughtSpecies(c)))
+	return nil
+}
+
+// findSpecies returns the species of a pokemon, most pokemon share the name of their
+// species but forms like deoxys-attack have to be looked up through the pokemon
+func findSpecies(ctx context.Context, c *models.Config, name string) (models.PokemonSpecies, error) {
+	species, err := c.Client.GetPokemonSpecies(ctx, name)
+	if !errors.Is(err, api.ErrNotFound) {
+		return species, err
+	}
+
+	p, err := findPokemon(ctx, c, name)
+	if err != nil {
+		return species, err
+	}
+	return c.Client.GetPokemonSpecies(ctx, p.Species.Name)
+}
+
+// caughtSpecies returns the species names of every pokemon in the pokedex
+func caughtSpecies(c *models.Config) map[string]bool {
+	caught := map[string]bool{}
+	for name, p := range c.Pokedex {
+		caught[name] = true
+		if p.Species.Name != "" {
+			caught[p.Species.Name] = true
+		}
+	}
+	return caught
+}
+
+// renderChain draws the chain as a tree, one species per line
+//
+//	eevee (caught)
+//	├─ vaporeon: use-item, water-stone
+//	└─ sylveon: level-up, happiness 160, knows a fairy move
+func renderChain(link models.ChainLink, caught map[string]bool) string {
+	var sb strings.Builder
+	sb.WriteString(chainLabel(link, caught) + "\n")
+	renderLinks(&sb, link.EvolvesTo, "", caught)
+	return sb.String()
+}
+
+// renderLinks draws the branches below a species, prefix holds the guide lines of the parents
+func renderLinks(sb *strings.Builder, links []models.ChainLink, prefix string, caught map[string]bool) {
+	for i, link := range links {
+		branch, indent := "├─ ", "│  "
+		if i == len(links)-1 {
+			branch, indent = "└─ ", "   "
+		}
+
+		sb.WriteString(prefix + branch + chainLabel(link, caught))
+		if how := describeEvolution(link.EvolutionDetails); how != "" {
+			sb.WriteString(": " + how)
+		}
+		sb.WriteString("\n")
+		renderLinks(sb, link.EvolvesTo, prefix+indent, caught)
+	}
+}
+
+// chainLabel returns the species name with its baby and caught markers
+func chainLabel(link models.ChainLink, caught map[string]bool) string {
+	label := link.Species.Name
+	if link.IsBaby {
+		label += " (baby)"
+	}
+	if caught[link.Species.Name] {
+		label += " (caught)"
+	}
+	return label
+}
+
+// describeEvolution summarizes how a species evolves, a species that evolves differently
+// across games has one detail per way which are joined with or
+func describeEvolution(details []models.EvolutionDetail) string {
+	var ways []string
+	for _, d := range details {
+		parts := []string{d.Trigger.Name}
+		if d.MinLevel > 0 {
+			parts = append(parts, fmt.Sprintf("level %d", d.MinLevel))
+		}
+		if d.Item.Name != "" {
+			parts = append(parts, d.Item.Name)
+		}
+		if d.HeldItem.Name != "" {
+			parts = append(parts, "holding "+d.HeldItem.Name)
+		}
+		if d.MinHappiness > 0 {
+			parts = append(parts, fmt.Sprintf("happiness %d", d.MinHappiness))
+		}
+		if d.MinAffection > 0 {
+			parts = append(parts, fmt.Sprintf("affection %d", d.MinAffection))
+		}
+		if d.MinBeauty > 0 {
+			parts = append(parts, fmt.Sprintf("beauty %d", d.MinBeauty))
+		}
+		if d.KnownMove.Name != "" {
+			parts = append(parts, "knows "+d.KnownMove.Name)
+		}
+		if d.KnownMoveType.Name != "" {
+			parts = append(parts, "knows a "+d.KnownMoveType.Name+" move")
+		}
+		if d.Location.Name != "" {
+			parts = append(parts, "at "+d.Location.Name)
+		}
+		if d.TimeOfDay != "" {
+			parts = append(parts, "during the "+d.TimeOfDay)
+		}
+		if d.PartySpecies.Name != "" {
+			parts = append(parts, d.PartySpecies.Name+" in the party")
+		}
+		if d.PartyType.Name != "" {
+			parts = append(parts, "a "+d.PartyType.Name+" type in the party")
+		}
+		if d.TradeSpecies.Name != "" {
+			parts = append(parts, "for "+d.TradeSpecies.Name)
+		}
+		if d.NeedsOverworldRain {
+			parts = append(parts, "while raining")
+		}
+		if d.TurnUpsideDown {
+			parts = append(parts, "upside down")
+		}
+
+		way := strings.Join(parts, ", ")
+		if !slices.Contains(ways, way) {
+			ways = append(ways, way)
+		}
+	}
+	return strings.Join(ways, " or ")
+}
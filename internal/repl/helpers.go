@@ -4,6 +4,8 @@ import (
 	"errors"
 	"math/rand"
 	"strings"
+
+	"github.com/joshhartwig/pokedex/pkg/models"
 )
 
 // cleanInput takes a string and returns a slice of strings
@@ -61,3 +63,48 @@ func checkArgs(wantedLength int, args []string) error {
 
 	return nil
 }
+
+// speciesFlavorText returns the most recent pokedex entry of the species in the language, or in
+// english when there is none, with the line and page breaks the api keeps from the games replaced by spaces
+func speciesFlavorText(s models.PokemonSpecies, lang string) string {
+	for _, l := range fallbackLanguages(lang) {
+		for i := len(s.FlavorTextEntries) - 1; i >= 0; i-- {
+			entry := s.FlavorTextEntries[i]
+			if strings.EqualFold(entry.Language.Name, l) {
+				return strings.Join(strings.Fields(entry.FlavorText), " ")
+			}
+		}
+	}
+	return ""
+}
+
+// speciesGenus returns the genus of the species in the language, or in english when
+// there is none, e.g. Mouse Pokémon
+func speciesGenus(s models.PokemonSpecies, lang string) string {
+	for _, l := range fallbackLanguages(lang) {
+		for _, g := range s.Genera {
+			if strings.EqualFold(g.Language.Name, l) {
+				return g.Genus
+			}
+		}
+	}
+	return ""
+}
+
+// englishShortEffect returns the english short effect of a move, ability or item
+func englishShortEffect(entries []models.Effect) string {
+	for _, e := range entries {
+		if e.Language.Name == "en" {
+			return strings.Join(strings.Fields(e.ShortEffect), " ")
+		}
+	}
+	return ""
+}
+
+// orUnknown returns s or unknown if s is empty, e.g. for species without a habitat
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
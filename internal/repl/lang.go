@@ -0,0 +1,92 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// Lang sets the language names are shown in, e.g. lang ja. Without a code it shows the
+// current language and lang none goes back to the api slugs. Commands keep taking slugs
+// as arguments whatever the language.
+func Lang(ctx context.Context, c *models.Config, args ...string) error {
+	if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+		if c.Language == "" {
+			fmt.Println("Names are shown as api slugs")
+		} else {
+			fmt.Printf("Names are shown in %s\n", c.Language)
+		}
+		return nil
+	}
+
+	if args[1] == "none" {
+		c.Language = ""
+		fmt.Println("Names are shown as api slugs")
+		return nil
+	}
+
+	c.Language = args[1]
+	fmt.Printf("Names are shown in %s, falling back to english\n", c.Language)
+	return nil
+}
+
+// fallbackLanguages returns the languages to look for a text in, the configured one then english
+func fallbackLanguages(lang string) []string {
+	if lang == "" || strings.EqualFold(lang, "en") {
+		return []string{"en"}
+	}
+	return []string{lang, "en"}
+}
+
+// localName returns the name in the configured language, or in english when the api has
+// none, followed by the slug so it can still be typed. Without a language it returns the slug.
+func localName(c *models.Config, names []models.Name, slug string) string {
+	if c.Language == "" {
+		return slug
+	}
+	for _, l := range fallbackLanguages(c.Language) {
+		for _, n := range names {
+			if strings.EqualFold(n.Language.Name, l) && n.Name != "" {
+				if n.Name == slug {
+					return slug
+				}
+				return fmt.Sprintf("%s (%s)", n.Name, slug)
+			}
+		}
+	}
+	return slug
+}
+
+// areaName returns the local name of a location area, it falls back to the slug when
+// the area can not be fetched
+func areaName(ctx context.Context, c *models.Config, slug string) string {
+	if c.Language == "" {
+		return slug
+	}
+	area, err := c.Client.GetLocationArea(ctx, slug)
+	if err != nil {
+		c.Logger.Debug("error fetching location area name", "location", slug, "error", err)
+		return slug
+	}
+	return localName(c, area.Names, slug)
+}
+
+// pokemonName returns the local name of a pokemon, which the api keeps on its species.
+// It falls back to the slug when the species can not be fetched.
+func pokemonName(ctx context.Context, c *models.Config, slug string) string {
+	if c.Language == "" {
+		return slug
+	}
+	species := slug
+	if p, ok := c.Pokedex[slug]; ok && p.Species.Name != "" {
+		species = p.Species.Name
+	}
+	s, err := c.Client.GetPokemonSpecies(ctx, species)
+	if err != nil {
+		c.Logger.Debug("error fetching pokemon name", "pokemon", slug, "error", err)
+		return slug
+	}
+	return localName(c, s.Names, slug)
+}
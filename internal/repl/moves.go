@@ -0,0 +1,167 @@
+package repl
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/joshhartwig/pokedex/internal/api"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// learnMethods is the order learn methods are listed in, any other method is listed after them
+var learnMethods = []string{"level-up", "machine", "egg", "tutor"}
+
+// learnedMove is a move a pokemon learns by a method, level is only set for level-up moves
+type learnedMove struct {
+	name  string
+	level int
+}
+
+// Moves displays the moves a pokemon can learn grouped by learn method, optionally
+// only the moves learnable in a version group such as red-blue or scarlet-violet.
+func Moves(ctx context.Context, c *models.Config, args ...string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return errors.New("usage: moves <pokemon> [version-group]")
+	}
+	character := args[1]
+	versionGroup := ""
+	if len(args) == 3 {
+		versionGroup = args[2]
+	}
+
+	pokemon, err := findPokemon(ctx, c, character)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such pokemon: %s\n", character)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching pokemon", "pokemon", character, "error", err)
+		return err
+	}
+
+	learnset := groupMoves(pokemon, versionGroup)
+	if len(learnset) == 0 {
+		if versionGroup == "" {
+			fmt.Printf("%s learns no moves\n", pokemon.Name)
+		} else {
+			fmt.Printf("%s learns no moves in %s\n", pokemon.Name, versionGroup)
+		}
+		return nil
+	}
+
+	if versionGroup == "" {
+		fmt.Printf("Moves of %s:\n", pokemon.Name)
+	} else {
+		fmt.Printf("Moves of %s in %s:\n", pokemon.Name, versionGroup)
+	}
+	for _, method := range sortedMethods(learnset) {
+		fmt.Printf("%s:\n", method)
+		for _, m := range learnset[method] {
+			if m.level > 0 {
+				fmt.Printf("  - lv %d %s\n", m.level, m.name)
+			} else {
+				fmt.Printf("  - %s\n", m.name)
+			}
+		}
+	}
+	return nil
+}
+
+// groupMoves returns the moves of a pokemon keyed by learn method, an empty version group
+// matches every version group and keeps the level of the latest one
+func groupMoves(p models.Pokemon, versionGroup string) map[string][]learnedMove {
+	learnset := map[string][]learnedMove{}
+	for _, m := range p.Moves {
+		levels := map[string]int{}
+		var methods []string
+		for _, d := range m.VersionGroupDetails {
+			if versionGroup != "" && d.VersionGroup.Name != versionGroup {
+				continue
+			}
+			method := d.MoveLearnMethod.Name
+			if _, ok := levels[method]; !ok {
+				methods = append(methods, method)
+			}
+			levels[method] = d.LevelLearnedAt
+		}
+		for _, method := range methods {
+			learnset[method] = append(learnset[method], learnedMove{name: m.Move.Name, level: levels[method]})
+		}
+	}
+
+	for _, moves := range learnset {
+		slices.SortFunc(moves, func(a, b learnedMove) int {
+			return cmp.Or(cmp.Compare(a.level, b.level), cmp.Compare(a.name, b.name))
+		})
+	}
+	return learnset
+}
+
+// sortedMethods returns the learn methods of the learnset in the order of learnMethods
+func sortedMethods(learnset map[string][]learnedMove) []string {
+	methods := make([]string, 0, len(learnset))
+	for method := range learnset {
+		methods = append(methods, method)
+	}
+	rank := func(method string) int {
+		if i := slices.Index(learnMethods, method); i >= 0 {
+			return i
+		}
+		return len(learnMethods)
+	}
+	slices.SortFunc(methods, func(a, b string) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), cmp.Compare(a, b))
+	})
+	return methods
+}
+
+// Move displays the details of a move.
+func Move(ctx context.Context, c *models.Config, args ...string) error {
+	if err := checkArgs(2, args); err != nil {
+		return errors.New("invalid move")
+	}
+	name := args[1]
+
+	move, err := c.Client.GetMove(ctx, name)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such move: %s\n", name)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching move", "move", name, "error", err)
+		return err
+	}
+
+	fmt.Printf("Name: %s\n", move.Name)
+	fmt.Printf("Type: %s\n", orUnknown(move.Type.Name))
+	fmt.Printf("Damage Class: %s\n", orUnknown(move.DamageClass.Name))
+	fmt.Printf("Power: %s\n", orNone(move.Power))
+	fmt.Printf("Accuracy: %s\n", orNone(move.Accuracy))
+	fmt.Printf("PP: %s\n", orNone(move.PP))
+	if effect := englishEffect(move); effect != "" {
+		fmt.Printf("Effect: %s\n", effect)
+	}
+	return nil
+}
+
+// englishEffect returns the english short effect of the move with the effect chance filled in
+func englishEffect(m models.Move) string {
+	effect := englishShortEffect(m.EffectEntries)
+	if m.EffectChance != nil {
+		effect = strings.ReplaceAll(effect, "$effect_chance", strconv.Itoa(*m.EffectChance))
+	}
+	return effect
+}
+
+// orNone formats an optional stat, or returns - when the move has none
+func orNone(v *int) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.Itoa(*v)
+}
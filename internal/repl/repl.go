@@ -2,43 +2,87 @@ package repl
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/joshhartwig/pokedex/pkg/models"
 )
 
 // Repl starts a Read-Eval-Print Loop for the Pokedex application.
 // It reads user input from the command line, processes commands, and executes the corresponding callbacks.
-// It will continue to prompt for input until the user exits the application.
-func Repl(c *models.Config) {
+// It will continue to prompt for input until the user runs exit or closes stdin.
+// Ctrl-C cancels the running command and returns to the prompt instead of quitting,
+// at the prompt it quits the application as usual.
+func Repl(ctx context.Context, c *models.Config) {
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
 		fmt.Printf("Pokedex > ")
-		if scanner.Scan() {
-			input := scanner.Text()
-			cleanedInput := cleanInput(input)
-
-			// user must enter a command
-			if len(cleanedInput) == 0 {
-				fmt.Println("Please enter a command. Type help for assistance.")
-				continue
-			}
-
-			cmd, ok := c.Commands[cleanedInput[0]]
-			if !ok {
-				fmt.Println("Uknown Command, type Help for assistance")
-				continue
-			}
-
-			// add the command to the history
-			c.History = append(c.History, cleanedInput[0])
-
-			err := cmd.Callback(cleanedInput...)
-			if err != nil {
-				fmt.Println("The command you just ran requires additional requirements", err)
-			}
+		if !scanner.Scan() {
+			return
+		}
+
+		input := scanner.Text()
+		cleanedInput := cleanInput(input)
+
+		// user must enter a command
+		if len(cleanedInput) == 0 {
+			fmt.Println("Please enter a command. Type help for assistance.")
+			continue
+		}
+
+		cmd, ok := c.Commands[cleanedInput[0]]
+		if !ok {
+			fmt.Println("Uknown Command, type Help for assistance")
+			continue
+		}
+
+		// add the command to the history
+		c.History = append(c.History, cleanedInput[0])
+
+		err := runCommand(ctx, cmd, cleanedInput)
+		if errors.Is(err, ErrExit) {
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("Command cancelled")
+			continue
+		}
+		if err != nil {
+			fmt.Println("The command you just ran requires additional requirements", err)
 		}
 	}
 }
+
+// runCommand runs the command callback and catches interrupts only while it runs, so
+// ctrl-c stops the command and not the session
+func runCommand(ctx context.Context, cmd models.CliCommand, args []string) error {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	return runInterruptible(ctx, cmd, interrupts, args)
+}
+
+// runInterruptible runs the command callback with a context that is cancelled when an
+// interrupt arrives on interrupts
+func runInterruptible(ctx context.Context, cmd models.CliCommand, interrupts <-chan os.Signal, args []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupts:
+			fmt.Println()
+			cancel()
+		case <-done:
+		}
+	}()
+
+	return cmd.Callback(ctx, args...)
+}
@@ -1,10 +1,167 @@
 package repl
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/joshhartwig/pokedex/internal/api"
+	"github.com/joshhartwig/pokedex/internal/database"
+	"github.com/joshhartwig/pokedex/internal/pokecache"
+	"github.com/joshhartwig/pokedex/pkg/models"
 )
 
+// fakeClient is an in memory models.ApiClient used to test commands without a network
+type fakeClient struct {
+	pages     map[int]models.Apiheader // keyed by offset
+	areas     map[string]models.LocationArea
+	pokemon   map[string]models.Pokemon
+	species   map[string]models.PokemonSpecies
+	types     map[string]models.Type
+	moves     map[string]models.Move
+	abilities map[string]models.Ability
+	items     map[string]models.Item
+	resources map[string]string // raw json returned by Fetch keyed by url
+	requested []string
+}
+
+func (f *fakeClient) ListLocationAreas(ctx context.Context, page models.PageCursor) (models.Apiheader, error) {
+	f.requested = append(f.requested, fmt.Sprintf("page:%d", page.Offset))
+	return f.pages[page.Offset], nil
+}
+
+func (f *fakeClient) GetLocationArea(ctx context.Context, name string) (models.LocationArea, error) {
+	f.requested = append(f.requested, "area:"+name)
+	la, ok := f.areas[name]
+	if !ok {
+		return la, &api.StatusError{Url: name, StatusCode: http.StatusNotFound}
+	}
+	return la, nil
+}
+
+func (f *fakeClient) GetPokemon(ctx context.Context, name string) (models.Pokemon, error) {
+	f.requested = append(f.requested, "pokemon:"+name)
+	p, ok := f.pokemon[name]
+	if !ok {
+		return p, &api.StatusError{Url: name, StatusCode: http.StatusNotFound}
+	}
+	return p, nil
+}
+
+func (f *fakeClient) GetPokemonSpecies(ctx context.Context, name string) (models.PokemonSpecies, error) {
+	f.requested = append(f.requested, "species:"+name)
+	ps, ok := f.species[name]
+	if !ok {
+		return ps, &api.StatusError{Url: name, StatusCode: http.StatusNotFound}
+	}
+	return ps, nil
+}
+
+func (f *fakeClient) GetType(ctx context.Context, name string) (models.Type, error) {
+	f.requested = append(f.requested, "type:"+name)
+	t, ok := f.types[name]
+	if !ok {
+		return t, &api.StatusError{Url: name, StatusCode: http.StatusNotFound}
+	}
+	return t, nil
+}
+
+func (f *fakeClient) GetMove(ctx context.Context, name string) (models.Move, error) {
+	f.requested = append(f.requested, "move:"+name)
+	m, ok := f.moves[name]
+	if !ok {
+		return m, &api.StatusError{Url: name, StatusCode: http.StatusNotFound}
+	}
+	return m, nil
+}
+
+func (f *fakeClient) GetAbility(ctx context.Context, name string) (models.Ability, error) {
+	f.requested = append(f.requested, "ability:"+name)
+	a, ok := f.abilities[name]
+	if !ok {
+		return a, &api.StatusError{Url: name, StatusCode: http.StatusNotFound}
+	}
+	return a, nil
+}
+
+func (f *fakeClient) GetItem(ctx context.Context, name string) (models.Item, error) {
+	f.requested = append(f.requested, "item:"+name)
+	i, ok := f.items[name]
+	if !ok {
+		return i, &api.StatusError{Url: name, StatusCode: http.StatusNotFound}
+	}
+	return i, nil
+}
+
+func (f *fakeClient) Fetch(ctx context.Context, url string, v any) error {
+	f.requested = append(f.requested, "fetch:"+url)
+	data, ok := f.resources[url]
+	if !ok {
+		return &api.StatusError{Url: url, StatusCode: http.StatusNotFound}
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+// fakeDb is an in memory database.Querier
+type fakeDb struct {
+	rows []database.Pokemon
+}
+
+func (f *fakeDb) AddPokemon(ctx context.Context, arg database.AddPokemonParams) (database.Pokemon, error) {
+	p := database.Pokemon{ID: arg.ID, PokemonName: arg.PokemonName, JsonData: arg.JsonData}
+	f.rows = append(f.rows, p)
+	return p, nil
+}
+
+func (f *fakeDb) GetPokemonByName(ctx context.Context, pokemonName string) (database.Pokemon, error) {
+	for _, p := range f.rows {
+		if p.PokemonName == pokemonName {
+			return p, nil
+		}
+	}
+	return database.Pokemon{}, sql.ErrNoRows
+}
+
+func (f *fakeDb) ListPokemon(ctx context.Context) ([]database.Pokemon, error) {
+	return f.rows, nil
+}
+
+func (f *fakeDb) DeleteCacheEntry(ctx context.Context, key string) error {
+	return nil
+}
+
+func (f *fakeDb) GetCacheEntry(ctx context.Context, key string) (database.CacheEntry, error) {
+	return database.CacheEntry{}, sql.ErrNoRows
+}
+
+func (f *fakeDb) ListCacheEntries(ctx context.Context) ([]database.CacheEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeDb) UpsertCacheEntry(ctx context.Context, arg database.UpsertCacheEntryParams) error {
+	return nil
+}
+
+func newTestConfig(client models.ApiClient) *models.Config {
+	return &models.Config{
+		Client:  client,
+		Db:      &fakeDb{},
+		Pokedex: map[string]models.Pokemon{},
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
 func TestCleanInput(t *testing.T) {
 	cases := []struct {
 		input  string
@@ -33,5 +190,939 @@ func TestCleanInput(t *testing.T) {
 }
 
 func TestCatch(t *testing.T) {
+	client := &fakeClient{
+		pokemon: map[string]models.Pokemon{"pikachu": {Name: "pikachu", BaseExperience: 112}},
+	}
+	c := newTestConfig(client)
+
+	if err := Catch(context.Background(), c, "catch", "pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(client.requested, []string{"pokemon:pikachu"}) {
+		t.Errorf("got requests %v want %v", client.requested, []string{"pokemon:pikachu"})
+	}
+
+	// the catch roll is random, a caught pokemon must be in both the pokedex and the db
+	db := c.Db.(*fakeDb)
+	if _, caught := c.Pokedex["pikachu"]; caught != (len(db.rows) == 1) {
+		t.Errorf("pokedex and db disagree, pokedex %v db %v", c.Pokedex, db.rows)
+	}
+
+	// a misspelled pokemon is reported and never thrown at
+	if err := Catch(context.Background(), c, "catch", "pikachuu"); err != nil {
+		t.Errorf("unexpected error for unknown pokemon: %v", err)
+	}
+	if _, ok := c.Pokedex["pikachuu"]; ok {
+		t.Errorf("unknown pokemon was added to the pokedex")
+	}
+
+	// catching a pokemon we already have is an error
+	c.Pokedex["pikachu"] = models.Pokemon{Name: "pikachu"}
+	if err := Catch(context.Background(), c, "catch", "pikachu"); err == nil {
+		t.Errorf("expected an error catching an already caught pokemon")
+	}
+}
+
+func TestExplore(t *testing.T) {
+	client := &fakeClient{areas: map[string]models.LocationArea{"canalave-city-area": {Name: "canalave-city-area"}}}
+	c := newTestConfig(client)
+
+	if err := Explore(context.Background(), c, "explore", " Canalave-City-Area "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"area:canalave-city-area"}
+	if !slices.Equal(client.requested, want) {
+		t.Errorf("got requests %v want %v", client.requested, want)
+	}
+
+	if err := Explore(context.Background(), c, "explore"); err == nil {
+		t.Errorf("expected an error without a location")
+	}
+}
+
+func TestReplReturnsOnExit(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unable to create pipe: %v", err)
+	}
+	fmt.Fprint(w, "exit\nhelp\n")
+	w.Close()
+
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	c := newTestConfig(nil)
+	c.Commands = map[string]models.CliCommand{
+		"exit": {Callback: func(ctx context.Context, args ...string) error { return Exit(ctx, c, args...) }},
+		"help": {Callback: func(ctx context.Context, args ...string) error {
+			t.Errorf("got a command after exit")
+			return nil
+		}},
+	}
+
+	captureOutput(t, func() { Repl(context.Background(), c) })
+}
+
+func TestRunCommandInterrupt(t *testing.T) {
+	interrupts := make(chan os.Signal, 1)
+	cmd := models.CliCommand{
+		Callback: func(ctx context.Context, args ...string) error {
+			interrupts <- os.Interrupt
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	err := runInterruptible(context.Background(), cmd, interrupts, []string{"test"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+}
+
+func TestRunCommandCatchesSigint(t *testing.T) {
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unable to find own process: %v", err)
+	}
+	cmd := models.CliCommand{
+		Callback: func(ctx context.Context, args ...string) error {
+			if err := self.Signal(os.Interrupt); err != nil {
+				t.Skipf("unable to send an interrupt: %v", err)
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	err = runCommand(context.Background(), cmd, []string{"test"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+}
+
+// newCassetteConfig returns a config whose client replays testdata/<name>.json, run the
+// tests with POKEDEX_RECORD=1 to record the cassette against the live api instead. The
+// tests only assert on names the live api serves, so they hold after a new recording.
+func newCassetteConfig(t *testing.T, name string) *models.Config {
+	t.Helper()
+	mode := api.ModeReplay
+	if os.Getenv("POKEDEX_RECORD") != "" {
+		mode = api.ModeRecord
+	}
+
+	cassette, err := api.NewCassette(filepath.Join("testdata", name+".json"), mode, nil)
+	if err != nil {
+		t.Fatalf("unable to load cassette: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := cassette.Save(); err != nil {
+			t.Errorf("unable to save cassette: %v", err)
+		}
+	})
+
+	opts := api.DefaultOptions()
+	opts.Transport = cassette
+	c := newTestConfig(nil)
+	c.Client = api.NewClient(pokecache.NewCache(time.Minute), c.Logger, opts)
+	return c
+}
+
+// captureOutput returns everything fn printed to stdout
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unable to create pipe: %v", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	return <-out
+}
+
+// the first two pages of location areas the api serves
+var (
+	firstAreaPage = []string{
+		"canalave-city-area", "eterna-city-area", "pastoria-city-area", "sunyshore-city-area",
+		"sinnoh-pokemon-league-area", "oreburgh-mine-1f", "oreburgh-mine-b1f", "valley-windworks-area",
+		"eterna-forest-area", "fuego-ironworks-area", "mt-coronet-1f-route-207", "mt-coronet-2f",
+		"mt-coronet-3f", "mt-coronet-exterior-snowfall", "mt-coronet-exterior-blizzard", "mt-coronet-4f",
+		"mt-coronet-4f-small-room", "mt-coronet-5f", "mt-coronet-6f", "mt-coronet-1f-from-exterior",
+	}
+	secondAreaPage = []string{
+		"mt-coronet-1f-route-216", "mt-coronet-1f-route-211", "mt-coronet-b1f", "great-marsh-area-1",
+		"great-marsh-area-2", "great-marsh-area-3", "great-marsh-area-4", "great-marsh-area-5",
+		"great-marsh-area-6", "solaceon-ruins-2f", "solaceon-ruins-1f", "solaceon-ruins-b1f-a",
+		"solaceon-ruins-b1f-b", "solaceon-ruins-b1f-c", "solaceon-ruins-b2f-a", "solaceon-ruins-b2f-b",
+		"solaceon-ruins-b2f-c", "solaceon-ruins-b3f-a", "solaceon-ruins-b3f-b", "solaceon-ruins-b3f-c",
+	}
+)
+
+// lines returns the output of a command printing one name per line
+func lines(names []string) string {
+	return strings.Join(names, "\n") + "\n"
+}
+
+func TestMapPagination(t *testing.T) {
+	c := newCassetteConfig(t, "map")
+	ctx := context.Background()
+
+	first, second := lines(firstAreaPage), lines(secondAreaPage)
+	steps := []struct {
+		cmd    func(context.Context, *models.Config, ...string) error
+		expect string // empty for the third page, which is checked on its own
+	}{
+		{cmd: Map, expect: first},
+		{cmd: Map, expect: second},
+		{cmd: Map},
+		{cmd: Mapb, expect: second},
+		{cmd: Mapb, expect: first},
+		{cmd: Mapb, expect: "you're on the first page\n"},
+		{cmd: Map, expect: second},
+	}
+
+	for i, step := range steps {
+		var err error
+		got := captureOutput(t, func() { err = step.cmd(ctx, c) })
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+
+		if step.expect != "" {
+			if got != step.expect {
+				t.Errorf("step %d: got %q want %q", i, got, step.expect)
+			}
+			continue
+		}
+		// a full page that is neither of the first two
+		if n := strings.Count(got, "\n"); n != models.DefaultPageLimit || got == first || got == second {
+			t.Errorf("step %d: got %d lines %q want the third page", i, n, got)
+		}
+	}
+}
+
+func TestMapbBeforeMap(t *testing.T) {
+	c := newCassetteConfig(t, "mapb")
+
+	var err error
+	got := captureOutput(t, func() { err = Mapb(context.Background(), c) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := lines(firstAreaPage); got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if c.MapPage == nil || !c.MapPage.IsFirst() {
+		t.Errorf("got page %v want the first page", c.MapPage)
+	}
+}
+
+func TestExploreCassette(t *testing.T) {
+	c := newCassetteConfig(t, "explore")
+	ctx := context.Background()
+
+	var err error
+	got := captureOutput(t, func() { err = Explore(ctx, c, "explore", "canalave-city-area") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Found Pokemon:\n" +
+		"- tentacool\n- tentacruel\n- staryu\n- magikarp\n- gyarados\n- wingull\n" +
+		"- pelipper\n- shellos\n- gastrodon\n- finneon\n- lumineon\n"
+	if got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Explore(ctx, c, "explore", "atlantis") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "no such location: atlantis\n" {
+		t.Errorf("got %q want %q", got, "no such location: atlantis\n")
+	}
+}
+
+func TestCatchCassette(t *testing.T) {
+	c := newCassetteConfig(t, "catch")
+	ctx := context.Background()
+
+	var err error
+	got := captureOutput(t, func() { err = Catch(ctx, c, "catch", "pikachu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(got, "Throwing a Pokeball at pikachu...\n") {
+		t.Errorf("got %q want a pokeball thrown at pikachu", got)
+	}
+
+	// the roll is random, but a caught pikachu must carry the api data
+	if p, caught := c.Pokedex["pikachu"]; caught && (p.BaseExperience != 112 || len(p.Stats) != 6) {
+		t.Errorf("got %+v want pikachu from the cassette", p)
+	}
+
+	got = captureOutput(t, func() { err = Catch(ctx, c, "catch", "pikachuu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "no such pokemon: pikachuu\n" {
+		t.Errorf("got %q want %q", got, "no such pokemon: pikachuu\n")
+	}
+}
+
+func TestInspectSpecies(t *testing.T) {
+	var species models.PokemonSpecies
+	err := json.Unmarshal([]byte(`{
+		"name": "pikachu",
+		"capture_rate": 190,
+		"is_legendary": false,
+		"is_mythical": false,
+		"habitat": {"name": "forest"},
+		"growth_rate": {"name": "medium"},
+		"genera": [{"genus": "Mausposi", "language": {"name": "de"}}, {"genus": "Mouse Pokémon", "language": {"name": "en"}}],
+		"flavor_text_entries": [
+			{"flavor_text": "When several of\nthese POKéMON\fgather, their\nelectricity could\nbuild and cause\nlightning storms.", "language": {"name": "en"}, "version": {"name": "red"}},
+			{"flavor_text": "It raises its tail\nto check its\fsurroundings.", "language": {"name": "en"}, "version": {"name": "gold"}},
+			{"flavor_text": "Il lui arrive de remettre en forme un Pikachu.", "language": {"name": "fr"}, "version": {"name": "x"}}
+		]
+	}`), &species)
+	if err != nil {
+		t.Fatalf("bad species json: %v", err)
+	}
+
+	client := &fakeClient{species: map[string]models.PokemonSpecies{"pikachu": species}}
+	c := newTestConfig(client)
+	c.Pokedex["pikachu"] = models.Pokemon{Name: "pikachu", Species: models.NamedResource{Name: "pikachu"}}
+
+	got := captureOutput(t, func() { err = Inspect(context.Background(), c, "inspect", "pikachu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"  Genus: Mouse Pokémon\n",
+		"  Description: It raises its tail to check its surroundings.\n",
+		"  Habitat: forest\n",
+		"  Growth Rate: medium\n",
+		"  Capture Rate: 190/255\n",
+		"  Legendary: false\n",
+		"  Mythical: false\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestEvolution(t *testing.T) {
+	const chainUrl = "https://pokeapi.co/api/v2/evolution-chain/67/"
+	client := &fakeClient{
+		species: map[string]models.PokemonSpecies{
+			"eevee": {Name: "eevee", EvolutionChain: models.ApiResource{URL: chainUrl}},
+		},
+		resources: map[string]string{chainUrl: `{
+			"id": 67,
+			"chain": {
+				"species": {"name": "eevee"},
+				"evolves_to": [
+					{
+						"species": {"name": "vaporeon"},
+						"evolution_details": [{"trigger": {"name": "use-item"}, "item": {"name": "water-stone"}}]
+					},
+					{
+						"species": {"name": "espeon"},
+						"evolution_details": [{"trigger": {"name": "level-up"}, "min_happiness": 160, "time_of_day": "day"}]
+					},
+					{
+						"species": {"name": "sylveon"},
+						"evolution_details": [
+							{"trigger": {"name": "level-up"}, "known_move_type": {"name": "fairy"}, "min_affection": 2},
+							{"trigger": {"name": "level-up"}, "known_move_type": {"name": "fairy"}, "min_affection": 2}
+						]
+					}
+				]
+			}
+		}`},
+	}
+	c := newTestConfig(client)
+	c.Pokedex["vaporeon"] = models.Pokemon{Name: "vaporeon", Species: models.NamedResource{Name: "vaporeon"}}
 
+	var err error
+	got := captureOutput(t, func() { err = Evolution(context.Background(), c, "evolution", "eevee") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Evolution chain of eevee:\n" +
+		"eevee\n" +
+		"├─ vaporeon (caught): use-item, water-stone\n" +
+		"├─ espeon: level-up, happiness 160, during the day\n" +
+		"└─ sylveon: level-up, affection 2, knows a fairy move\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEvolutionNestedChain(t *testing.T) {
+	chain := models.ChainLink{
+		Species: models.NamedResource{Name: "pichu"},
+		IsBaby:  true,
+		EvolvesTo: []models.ChainLink{{
+			Species:          models.NamedResource{Name: "pikachu"},
+			EvolutionDetails: []models.EvolutionDetail{{Trigger: models.NamedResource{Name: "level-up"}, MinHappiness: 220}},
+			EvolvesTo: []models.ChainLink{{
+				Species:          models.NamedResource{Name: "raichu"},
+				EvolutionDetails: []models.EvolutionDetail{{Trigger: models.NamedResource{Name: "use-item"}, Item: models.NamedResource{Name: "thunder-stone"}}},
+			}},
+		}},
+	}
+
+	got := renderChain(chain, map[string]bool{"pikachu": true})
+	want := "pichu (baby)\n" +
+		"└─ pikachu (caught): level-up, happiness 220\n" +
+		"   └─ raichu: use-item, thunder-stone\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEvolutionUnknownPokemon(t *testing.T) {
+	c := newTestConfig(&fakeClient{})
+
+	var err error
+	got := captureOutput(t, func() { err = Evolution(context.Background(), c, "evolution", "missingno") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "no such pokemon: missingno\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// groundType is the /type/ground damage relations used by the matchup tests
+var groundType = models.Type{
+	Name: "ground",
+	DamageRelations: models.TypeRelations{
+		DoubleDamageTo: []models.NamedResource{{Name: "poison"}, {Name: "rock"}, {Name: "steel"}, {Name: "fire"}, {Name: "electric"}},
+		HalfDamageTo:   []models.NamedResource{{Name: "bug"}, {Name: "grass"}},
+		NoDamageTo:     []models.NamedResource{{Name: "flying"}},
+	},
+}
+
+func TestEffectiveness(t *testing.T) {
+	cases := []struct {
+		defenders []string
+		expect    float64
+	}{
+		{defenders: []string{"normal"}, expect: 1},
+		{defenders: []string{"fire"}, expect: 2},
+		{defenders: []string{"grass"}, expect: 0.5},
+		{defenders: []string{"fire", "rock"}, expect: 4},
+		{defenders: []string{"grass", "bug"}, expect: 0.25},
+		{defenders: []string{"grass", "poison"}, expect: 1},
+		{defenders: []string{"fire", "flying"}, expect: 0},
+	}
+
+	for _, c := range cases {
+		if got := effectiveness(groundType, c.defenders...); got != c.expect {
+			t.Errorf("ground against %v: got %v want %v", c.defenders, got, c.expect)
+		}
+	}
+}
+
+func TestMatchup(t *testing.T) {
+	var charizard models.Pokemon
+	err := json.Unmarshal([]byte(`{"name": "charizard", "types": [{"slot": 1, "type": {"name": "fire"}}, {"slot": 2, "type": {"name": "flying"}}]}`), &charizard)
+	if err != nil {
+		t.Fatalf("bad pokemon json: %v", err)
+	}
+
+	client := &fakeClient{
+		types:   map[string]models.Type{"ground": groundType},
+		pokemon: map[string]models.Pokemon{"charizard": charizard},
+	}
+	c := newTestConfig(client)
+
+	got := captureOutput(t, func() { err = Matchup(context.Background(), c, "matchup", "ground", "charizard") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "ground against charizard (fire/flying): 0x, no effect\n" +
+		"  - fire: 2x\n" +
+		"  - flying: 0x\n"
+	if got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Matchup(context.Background(), c, "matchup", "shadow", "charizard") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "no such type: shadow\n" {
+		t.Errorf("got %q", got)
+	}
+
+	for _, args := range [][]string{{"matchup", "ground"}, {"matchup", "ground", "charizard", "extra"}} {
+		if err := Matchup(context.Background(), c, args...); err == nil {
+			t.Errorf("%v: expected a usage error", args)
+		}
+	}
+}
+
+func TestMoves(t *testing.T) {
+	var pikachu models.Pokemon
+	err := json.Unmarshal([]byte(`{"name": "pikachu", "moves": [
+		{"move": {"name": "thunder-shock"}, "version_group_details": [
+			{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "red-blue"}},
+			{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "scarlet-violet"}}
+		]},
+		{"move": {"name": "thunderbolt"}, "version_group_details": [
+			{"level_learned_at": 0, "move_learn_method": {"name": "machine"}, "version_group": {"name": "red-blue"}},
+			{"level_learned_at": 36, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "scarlet-violet"}}
+		]},
+		{"move": {"name": "thunder-wave"}, "version_group_details": [
+			{"level_learned_at": 9, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "red-blue"}}
+		]},
+		{"move": {"name": "volt-tackle"}, "version_group_details": [
+			{"level_learned_at": 0, "move_learn_method": {"name": "egg"}, "version_group": {"name": "scarlet-violet"}}
+		]}
+	]}`), &pikachu)
+	if err != nil {
+		t.Fatalf("bad pokemon json: %v", err)
+	}
+
+	ditto := models.Pokemon{Name: "ditto"}
+	c := newTestConfig(&fakeClient{pokemon: map[string]models.Pokemon{"pikachu": pikachu, "ditto": ditto}})
+
+	got := captureOutput(t, func() { err = Moves(context.Background(), c, "moves", "pikachu", "red-blue") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Moves of pikachu in red-blue:\n" +
+		"level-up:\n" +
+		"  - lv 1 thunder-shock\n" +
+		"  - lv 9 thunder-wave\n" +
+		"machine:\n" +
+		"  - thunderbolt\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Moves(context.Background(), c, "moves", "pikachu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = "Moves of pikachu:\n" +
+		"level-up:\n" +
+		"  - lv 1 thunder-shock\n" +
+		"  - lv 9 thunder-wave\n" +
+		"  - lv 36 thunderbolt\n" +
+		"machine:\n" +
+		"  - thunderbolt\n" +
+		"egg:\n" +
+		"  - volt-tackle\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Moves(context.Background(), c, "moves", "pikachu", "gold-silver") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "pikachu learns no moves in gold-silver\n" {
+		t.Errorf("got %q", got)
+	}
+
+	got = captureOutput(t, func() { err = Moves(context.Background(), c, "moves", "ditto") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "ditto learns no moves\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestMove(t *testing.T) {
+	var thunderbolt, growl models.Move
+	err := json.Unmarshal([]byte(`{
+		"name": "thunderbolt", "power": 90, "accuracy": 100, "pp": 15, "effect_chance": 10,
+		"type": {"name": "electric"}, "damage_class": {"name": "special"},
+		"effect_entries": [{"short_effect": "Has a $effect_chance% chance to\nparalyze the target.", "language": {"name": "en"}}]
+	}`), &thunderbolt)
+	if err != nil {
+		t.Fatalf("bad move json: %v", err)
+	}
+	err = json.Unmarshal([]byte(`{"name": "growl", "power": null, "accuracy": 100, "pp": 40, "type": {"name": "normal"}, "damage_class": {"name": "status"}}`), &growl)
+	if err != nil {
+		t.Fatalf("bad move json: %v", err)
+	}
+
+	c := newTestConfig(&fakeClient{moves: map[string]models.Move{"thunderbolt": thunderbolt, "growl": growl}})
+
+	got := captureOutput(t, func() { err = Move(context.Background(), c, "move", "thunderbolt") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Name: thunderbolt\n" +
+		"Type: electric\n" +
+		"Damage Class: special\n" +
+		"Power: 90\n" +
+		"Accuracy: 100\n" +
+		"PP: 15\n" +
+		"Effect: Has a 10% chance to paralyze the target.\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Move(context.Background(), c, "move", "growl") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "Power: -\n") {
+		t.Errorf("got %q want a status move without power", got)
+	}
+}
+
+func TestInspectAbilitiesAndHeldItems(t *testing.T) {
+	var pikachu models.Pokemon
+	err := json.Unmarshal([]byte(`{
+		"name": "pikachu",
+		"species": {"name": "pikachu"},
+		"abilities": [{"ability": {"name": "static"}, "is_hidden": false, "slot": 1}, {"ability": {"name": "lightning-rod"}, "is_hidden": true, "slot": 3}],
+		"held_items": [{"item": {"name": "oran-berry"}, "version_details": [{"rarity": 50, "version": {"name": "ruby"}}, {"rarity": 5, "version": {"name": "emerald"}}]}]
+	}`), &pikachu)
+	if err != nil {
+		t.Fatalf("bad pokemon json: %v", err)
+	}
+
+	c := newTestConfig(&fakeClient{})
+	c.Pokedex["pikachu"] = pikachu
+
+	got := captureOutput(t, func() { err = Inspect(context.Background(), c, "inspect", "pikachu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Abilities:\n" +
+		"  - static\n" +
+		"  - lightning-rod (hidden)\n" +
+		"Held Items:\n" +
+		"  - oran-berry: ruby 50%, emerald 5%\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("got %q want it to contain %q", got, want)
+	}
+}
+
+func TestAbility(t *testing.T) {
+	var static models.Ability
+	err := json.Unmarshal([]byte(`{
+		"name": "static", "generation": {"name": "generation-iii"},
+		"effect_entries": [{"short_effect": "Has a 30% chance of paralyzing attacking Pokémon on contact.", "language": {"name": "en"}}],
+		"pokemon": [{"is_hidden": false, "pokemon": {"name": "pikachu"}}, {"is_hidden": true, "pokemon": {"name": "electrike"}}]
+	}`), &static)
+	if err != nil {
+		t.Fatalf("bad ability json: %v", err)
+	}
+
+	c := newTestConfig(&fakeClient{abilities: map[string]models.Ability{"static": static}})
+
+	got := captureOutput(t, func() { err = Ability(context.Background(), c, "ability", "static") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Name: static\n" +
+		"Generation: generation-iii\n" +
+		"Effect: Has a 30% chance of paralyzing attacking Pokémon on contact.\n" +
+		"Pokemon:\n" +
+		"  - pikachu\n" +
+		"  - electrike (hidden)\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestItem(t *testing.T) {
+	var berry models.Item
+	err := json.Unmarshal([]byte(`{
+		"name": "oran-berry", "cost": 20, "category": {"name": "medicine"},
+		"effect_entries": [{"short_effect": "Restores 10 HP\nwhen held.", "language": {"name": "en"}}],
+		"held_by_pokemon": [{"pokemon": {"name": "pikachu"}, "version_details": [{"rarity": 50, "version": {"name": "ruby"}}]}]
+	}`), &berry)
+	if err != nil {
+		t.Fatalf("bad item json: %v", err)
+	}
+
+	c := newTestConfig(&fakeClient{items: map[string]models.Item{"oran-berry": berry}})
+
+	got := captureOutput(t, func() { err = Item(context.Background(), c, "item", "oran-berry") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Name: oran-berry\n" +
+		"Category: medicine\n" +
+		"Cost: 20\n" +
+		"Effect: Restores 10 HP when held.\n" +
+		"Held By:\n" +
+		"  - pikachu: ruby 50%\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Item(context.Background(), c, "item", "master-sword") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "no such item: master-sword\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	const encountersUrl = "https://pokeapi.co/api/v2/pokemon/25/encounters"
+	client := &fakeClient{
+		pokemon: map[string]models.Pokemon{"pikachu": {Name: "pikachu", LocationAreaEncounters: encountersUrl}},
+		resources: map[string]string{encountersUrl: `[
+			{"location_area": {"name": "viridian-forest-area"}, "version_details": [
+				{"version": {"name": "red"}, "max_chance": 10, "encounter_details": [
+					{"chance": 5, "min_level": 3, "max_level": 3, "method": {"name": "walk"}},
+					{"chance": 5, "min_level": 5, "max_level": 5, "method": {"name": "walk"}}
+				]}
+			]},
+			{"location_area": {"name": "power-plant-area"}, "version_details": [
+				{"version": {"name": "red"}, "max_chance": 25, "encounter_details": [
+					{"chance": 25, "min_level": 22, "max_level": 24, "method": {"name": "walk"}}
+				]},
+				{"version": {"name": "blue"}, "max_chance": 25, "encounter_details": [
+					{"chance": 15, "min_level": 22, "max_level": 22, "method": {"name": "walk"}}
+				]}
+			]}
+		]`},
+	}
+	c := newTestConfig(client)
+
+	var err error
+	got := captureOutput(t, func() { err = Where(context.Background(), c, "where", "pikachu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "pikachu can be found in:\n" +
+		"  - viridian-forest-area (red): walk, lv 3-5, 10%\n" +
+		"  - power-plant-area (red): walk, lv 22-24, 25%\n" +
+		"  - power-plant-area (blue): walk, lv 22, 15%\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Where(context.Background(), c, "where", "pikachu", "chance") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = "pikachu can be found in:\n" +
+		"  - power-plant-area (red): walk, lv 22-24, 25%\n" +
+		"  - power-plant-area (blue): walk, lv 22, 15%\n" +
+		"  - viridian-forest-area (red): walk, lv 3-5, 10%\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWhereNotInTheWild(t *testing.T) {
+	const encountersUrl = "https://pokeapi.co/api/v2/pokemon/151/encounters"
+	client := &fakeClient{
+		pokemon:   map[string]models.Pokemon{"mew": {Name: "mew", LocationAreaEncounters: encountersUrl}},
+		resources: map[string]string{encountersUrl: `[]`},
+	}
+	c := newTestConfig(client)
+
+	var err error
+	got := captureOutput(t, func() { err = Where(context.Background(), c, "where", "mew") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "mew can not be found in the wild\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// japaneseClient serves the names used by the lang tests, bulbasaur only has an english
+// name and the species of missingno does not exist
+func japaneseClient(t *testing.T) *fakeClient {
+	var area models.LocationArea
+	err := json.Unmarshal([]byte(`{
+		"name": "viridian-forest-area",
+		"names": [{"name": "Viridian Forest", "language": {"name": "en"}}, {"name": "トキワのもり", "language": {"name": "ja-Hrkt"}}],
+		"pokemon_encounters": [{"pokemon": {"name": "pikachu"}}, {"pokemon": {"name": "bulbasaur"}}, {"pokemon": {"name": "missingno"}}]
+	}`), &area)
+	if err != nil {
+		t.Fatalf("bad area json: %v", err)
+	}
+
+	var pikachu, bulbasaur models.PokemonSpecies
+	err = json.Unmarshal([]byte(`{
+		"name": "pikachu",
+		"names": [{"name": "Pikachu", "language": {"name": "en"}}, {"name": "ピカチュウ", "language": {"name": "ja-Hrkt"}}],
+		"genera": [{"genus": "Mouse Pokémon", "language": {"name": "en"}}, {"genus": "ねずみポケモン", "language": {"name": "ja-Hrkt"}}]
+	}`), &pikachu)
+	if err != nil {
+		t.Fatalf("bad species json: %v", err)
+	}
+	err = json.Unmarshal([]byte(`{"name": "bulbasaur", "names": [{"name": "Bulbasaur", "language": {"name": "en"}}]}`), &bulbasaur)
+	if err != nil {
+		t.Fatalf("bad species json: %v", err)
+	}
+
+	return &fakeClient{
+		pages:   map[int]models.Apiheader{0: {Results: []models.Location{{Name: "viridian-forest-area"}, {Name: "route-1-area"}}}},
+		areas:   map[string]models.LocationArea{"viridian-forest-area": area},
+		species: map[string]models.PokemonSpecies{"pikachu": pikachu, "bulbasaur": bulbasaur},
+	}
+}
+
+func TestLangMapAndExplore(t *testing.T) {
+	c := newTestConfig(japaneseClient(t))
+	if err := Lang(context.Background(), c, "lang", "ja-hrkt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var err error
+	got := captureOutput(t, func() { err = Map(context.Background(), c, "map") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "トキワのもり (viridian-forest-area)\n" +
+		"route-1-area\n"
+	if got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+
+	// the slug is still accepted after switching language
+	got = captureOutput(t, func() { err = Explore(context.Background(), c, "explore", "viridian-forest-area") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = "Found Pokemon:\n" +
+		"- ピカチュウ (pikachu)\n" +
+		"- Bulbasaur (bulbasaur)\n" +
+		"- missingno\n"
+	if got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+func TestLangInspect(t *testing.T) {
+	c := newTestConfig(japaneseClient(t))
+	c.Pokedex["pikachu"] = models.Pokemon{Name: "pikachu", Species: models.NamedResource{Name: "pikachu"}}
+
+	var err error
+	got := captureOutput(t, func() { err = Inspect(context.Background(), c, "inspect", "pikachu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Name: pikachu\n", "  Genus: Mouse Pokémon\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q want it to contain %q", got, want)
+		}
+	}
+
+	c.Language = "ja-Hrkt"
+	got = captureOutput(t, func() { err = Inspect(context.Background(), c, "inspect", "pikachu") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Name: ピカチュウ (pikachu)\n", "  Genus: ねずみポケモン\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestLangNone(t *testing.T) {
+	c := newTestConfig(japaneseClient(t))
+	c.Language = "ja-Hrkt"
+
+	var err error
+	got := captureOutput(t, func() { err = Lang(context.Background(), c, "lang", "none") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Language != "" || got != "Names are shown as api slugs\n" {
+		t.Errorf("got language %q and output %q", c.Language, got)
+	}
+
+	got = captureOutput(t, func() { err = Map(context.Background(), c, "map") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "viridian-forest-area\nroute-1-area\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCacheCommand(t *testing.T) {
+	const root = "https://pokeapi.co/api/v2/"
+	c := newTestConfig(&fakeClient{})
+	c.ApiRoot = root
+	cache := pokecache.NewCache(time.Hour)
+	defer cache.Close()
+	c.Cache = cache
+
+	cache.Add(root+"pokemon/pikachu/", pokecache.CacheEntry{Val: []byte("pikachu")})
+	cache.Add(root+"pokemon/eevee/", pokecache.CacheEntry{Val: []byte("eevee"), ETag: `"v1"`, CreatedAt: time.Now().Add(-2 * time.Hour)})
+	cache.Add(root+"location-area/canalave-city-area/", pokecache.CacheEntry{Val: []byte("area")})
+	cache.Get(root + "pokemon/pikachu/")
+	cache.Get(root + "pokemon/mew/")
+
+	var err error
+	got := captureOutput(t, func() { err = Cache(context.Background(), c, "cache", "list", "pokemon/") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "pokemon/eevee/ (5 bytes, stale)\n" +
+		"pokemon/pikachu/ (7 bytes, fresh)\n"
+	if got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+
+	got = captureOutput(t, func() { err = Cache(context.Background(), c, "cache", "stats") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"  Hits: 1\n", "  Misses: 1\n", "  Hit Rate: 50.0%\n", "  Entries: 3\n", "  - location-area: 1\n", "  - pokemon: 2\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q want it to contain %q", got, want)
+		}
+	}
+
+	got = captureOutput(t, func() { err = Cache(context.Background(), c, "cache", "clear", "pokemon/") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Removed 2 cached entries\n" {
+		t.Errorf("got %q", got)
+	}
+	if _, ok := cache.Get(root + "location-area/canalave-city-area/"); !ok {
+		t.Errorf("expected entries outside the prefix to be kept")
+	}
+
+	got = captureOutput(t, func() { err = Cache(context.Background(), c, "cache", "clear") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Removed 1 cached entries\n" {
+		t.Errorf("got %q", got)
+	}
 }
@@ -0,0 +1,151 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joshhartwig/pokedex/internal/api"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// Types displays the damage relations of a type, both when attacking with it and when defending as it.
+func Types(ctx context.Context, c *models.Config, args ...string) error {
+	if err := checkArgs(2, args); err != nil {
+		return errors.New("invalid type")
+	}
+	name := args[1]
+
+	t, err := c.Client.GetType(ctx, name)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such type: %s\n", name)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching type", "type", name, "error", err)
+		return err
+	}
+
+	r := t.DamageRelations
+	fmt.Printf("Type: %s\n", t.Name)
+	fmt.Println("Attacking:")
+	fmt.Printf("  2x against: %s\n", typeNames(r.DoubleDamageTo))
+	fmt.Printf("  0.5x against: %s\n", typeNames(r.HalfDamageTo))
+	fmt.Printf("  0x against: %s\n", typeNames(r.NoDamageTo))
+	fmt.Println("Defending:")
+	fmt.Printf("  2x from: %s\n", typeNames(r.DoubleDamageFrom))
+	fmt.Printf("  0.5x from: %s\n", typeNames(r.HalfDamageFrom))
+	fmt.Printf("  0x from: %s\n", typeNames(r.NoDamageFrom))
+	return nil
+}
+
+// Matchup displays how effective moves of a type are against a pokemon, dual type
+// pokemon take the product of the multipliers of both their types.
+func Matchup(ctx context.Context, c *models.Config, args ...string) error {
+	if len(args) != 3 || checkArgs(3, args) != nil {
+		return errors.New("usage: matchup <attacker-type> <defender-pokemon>")
+	}
+	typeName, character := args[1], args[2]
+
+	attack, err := c.Client.GetType(ctx, typeName)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such type: %s\n", typeName)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching type", "type", typeName, "error", err)
+		return err
+	}
+
+	pokemon, err := findPokemon(ctx, c, character)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such pokemon: %s\n", character)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching pokemon", "pokemon", character, "error", err)
+		return err
+	}
+
+	var defenders []string
+	for _, t := range pokemon.Types {
+		defenders = append(defenders, t.Type.Name)
+	}
+
+	m := effectiveness(attack, defenders...)
+	fmt.Printf("%s against %s (%s): %s, %s\n", attack.Name, pokemon.Name, strings.Join(defenders, "/"), multiplier(m), describeEffectiveness(m))
+	if len(defenders) > 1 {
+		for _, d := range defenders {
+			fmt.Printf("  - %s: %s\n", d, multiplier(effectiveness(attack, d)))
+		}
+	}
+	return nil
+}
+
+// findPokemon returns a pokemon from the pokedex, or from the api when it has not been caught
+func findPokemon(ctx context.Context, c *models.Config, name string) (models.Pokemon, error) {
+	if p, ok := c.Pokedex[name]; ok {
+		return p, nil
+	}
+	return c.Client.GetPokemon(ctx, name)
+}
+
+// effectiveness returns the damage multiplier of a move of the attacking type against
+// a pokemon of the defending types, e.g. 4 for ground against a fire/rock pokemon
+func effectiveness(attack models.Type, defenders ...string) float64 {
+	m := 1.0
+	for _, d := range defenders {
+		switch {
+		case hasType(attack.DamageRelations.NoDamageTo, d):
+			m *= 0
+		case hasType(attack.DamageRelations.DoubleDamageTo, d):
+			m *= 2
+		case hasType(attack.DamageRelations.HalfDamageTo, d):
+			m *= 0.5
+		}
+	}
+	return m
+}
+
+// hasType reports whether the named type is in the list
+func hasType(types []models.NamedResource, name string) bool {
+	for _, t := range types {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// typeNames joins the names of the types, or returns none for an empty list
+func typeNames(types []models.NamedResource) string {
+	if len(types) == 0 {
+		return "none"
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// multiplier formats a damage multiplier as 0x, 0.25x, 1x, 4x...
+func multiplier(m float64) string {
+	return strconv.FormatFloat(m, 'g', -1, 64) + "x"
+}
+
+// describeEffectiveness returns the in game message for a damage multiplier
+func describeEffectiveness(m float64) string {
+	switch {
+	case m == 0:
+		return "no effect"
+	case m < 1:
+		return "not very effective"
+	case m > 1:
+		return "super effective"
+	default:
+		return "normal damage"
+	}
+}
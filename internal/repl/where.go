@@ -0,0 +1,104 @@
+package repl
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/joshhartwig/pokedex/internal/api"
+	"github.com/joshhartwig/pokedex/pkg/models"
+)
+
+// encounterRow is every way to meet a pokemon with one method in an area and version
+type encounterRow struct {
+	area     string
+	version  string
+	method   string
+	minLevel int
+	maxLevel int
+	chance   int
+}
+
+// Where displays the location areas a pokemon can be encountered in, with the version, method,
+// level range and chance of each. Passing chance sorts the areas from the most likely encounter.
+func Where(ctx context.Context, c *models.Config, args ...string) error {
+	if len(args) < 2 || len(args) > 3 || (len(args) == 3 && args[2] != "chance") {
+		return errors.New("usage: where <pokemon> [chance]")
+	}
+	character := args[1]
+
+	pokemon, err := findPokemon(ctx, c, character)
+	if errors.Is(err, api.ErrNotFound) {
+		fmt.Printf("no such pokemon: %s\n", character)
+		return nil
+	}
+	if err != nil {
+		c.Logger.Error("error fetching pokemon", "pokemon", character, "error", err)
+		return err
+	}
+
+	var encounters []models.LocationAreaEncounter
+	if pokemon.LocationAreaEncounters != "" {
+		if err := c.Client.Fetch(ctx, pokemon.LocationAreaEncounters, &encounters); err != nil {
+			c.Logger.Error("error fetching encounters", "url", pokemon.LocationAreaEncounters, "error", err)
+			return err
+		}
+	}
+
+	rows := encounterRows(encounters)
+	if len(rows) == 0 {
+		fmt.Printf("%s can not be found in the wild\n", pokemon.Name)
+		return nil
+	}
+	if len(args) == 3 {
+		slices.SortStableFunc(rows, func(a, b encounterRow) int {
+			return cmp.Compare(b.chance, a.chance)
+		})
+	}
+
+	fmt.Printf("%s can be found in:\n", pokemon.Name)
+	for _, r := range rows {
+		fmt.Printf("  - %s (%s): %s, %s, %d%%\n", r.area, r.version, r.method, levelRange(r.minLevel, r.maxLevel), r.chance)
+	}
+	return nil
+}
+
+// encounterRows flattens the encounters into one row per area, version and method. An area
+// often has several slots for the same method, their chances are added up and their levels merged.
+func encounterRows(encounters []models.LocationAreaEncounter) []encounterRow {
+	var rows []encounterRow
+	for _, e := range encounters {
+		for _, v := range e.VersionDetails {
+			start := len(rows)
+			for _, d := range v.EncounterDetails {
+				i := slices.IndexFunc(rows[start:], func(r encounterRow) bool { return r.method == d.Method.Name })
+				if i < 0 {
+					rows = append(rows, encounterRow{
+						area:     e.LocationArea.Name,
+						version:  v.Version.Name,
+						method:   d.Method.Name,
+						minLevel: d.MinLevel,
+						maxLevel: d.MaxLevel,
+						chance:   d.Chance,
+					})
+					continue
+				}
+				r := &rows[start+i]
+				r.minLevel = min(r.minLevel, d.MinLevel)
+				r.maxLevel = max(r.maxLevel, d.MaxLevel)
+				r.chance += d.Chance
+			}
+		}
+	}
+	return rows
+}
+
+// levelRange formats the levels of an encounter, e.g. lv 3-5 or lv 10
+func levelRange(minLevel, maxLevel int) string {
+	if minLevel == maxLevel {
+		return fmt.Sprintf("lv %d", minLevel)
+	}
+	return fmt.Sprintf("lv %d-%d", minLevel, maxLevel)
+}
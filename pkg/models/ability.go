@@ -0,0 +1,53 @@
+package models
+
+// Ability is a passive effect a pokemon has in battle, a pokemon has one or two
+// regular abilities and can have a hidden one
+type Ability struct {
+	EffectEntries     []Effect `json:"effect_entries,omitempty"`
+	FlavorTextEntries []struct {
+		FlavorText   string        `json:"flavor_text,omitempty"`
+		Language     NamedResource `json:"language,omitempty"`
+		VersionGroup NamedResource `json:"version_group,omitempty"`
+	} `json:"flavor_text_entries,omitempty"`
+	Generation   NamedResource `json:"generation,omitempty"`
+	ID           int           `json:"id,omitempty"`
+	IsMainSeries bool          `json:"is_main_series,omitempty"`
+	Name         string        `json:"name,omitempty"`
+	Names        []Name        `json:"names,omitempty"`
+	Pokemon      []struct {
+		IsHidden bool          `json:"is_hidden,omitempty"`
+		Pokemon  NamedResource `json:"pokemon,omitempty"`
+		Slot     int           `json:"slot,omitempty"`
+	} `json:"pokemon,omitempty"`
+}
+
+// Item is an object in the games, such as a ball, a berry or an item a wild pokemon can hold
+type Item struct {
+	Attributes        []NamedResource `json:"attributes,omitempty"`
+	Category          NamedResource   `json:"category,omitempty"`
+	Cost              int             `json:"cost,omitempty"`
+	EffectEntries     []Effect        `json:"effect_entries,omitempty"`
+	FlavorTextEntries []struct {
+		Language     NamedResource `json:"language,omitempty"`
+		Text         string        `json:"text,omitempty"`
+		VersionGroup NamedResource `json:"version_group,omitempty"`
+	} `json:"flavor_text_entries,omitempty"`
+	FlingEffect   NamedResource `json:"fling_effect,omitempty"`
+	FlingPower    int           `json:"fling_power,omitempty"`
+	HeldByPokemon []struct {
+		Pokemon        NamedResource   `json:"pokemon,omitempty"`
+		VersionDetails []VersionRarity `json:"version_details,omitempty"`
+	} `json:"held_by_pokemon,omitempty"`
+	ID      int    `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Names   []Name `json:"names,omitempty"`
+	Sprites struct {
+		Default string `json:"default,omitempty"`
+	} `json:"sprites,omitempty"`
+}
+
+// VersionRarity is the chance in percent that a wild pokemon holds an item in a game version
+type VersionRarity struct {
+	Rarity  int           `json:"rarity,omitempty"`
+	Version NamedResource `json:"version,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// LocationAreaEncounter is a location area a pokemon can be encountered in, the
+// pokemon's LocationAreaEncounters url returns a list of them
+type LocationAreaEncounter struct {
+	LocationArea   NamedResource            `json:"location_area,omitempty"`
+	VersionDetails []VersionEncounterDetail `json:"version_details,omitempty"`
+}
+
+// VersionEncounterDetail holds the encounters of a pokemon in one game version
+type VersionEncounterDetail struct {
+	EncounterDetails []Encounter   `json:"encounter_details,omitempty"`
+	MaxChance        int           `json:"max_chance,omitempty"`
+	Version          NamedResource `json:"version,omitempty"`
+}
+
+// Encounter is one way to meet a pokemon, chance is in percent
+type Encounter struct {
+	Chance          int           `json:"chance,omitempty"`
+	ConditionValues []any         `json:"condition_values,omitempty"`
+	MaxLevel        int           `json:"max_level,omitempty"`
+	Method          NamedResource `json:"method,omitempty"`
+	MinLevel        int           `json:"min_level,omitempty"`
+}
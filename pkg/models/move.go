@@ -0,0 +1,31 @@
+package models
+
+// Move is a move a pokemon can learn, Power, Accuracy and PP are nil for moves
+// that have none, e.g. status moves have no power
+type Move struct {
+	Accuracy          *int          `json:"accuracy,omitempty"`
+	DamageClass       NamedResource `json:"damage_class,omitempty"`
+	EffectChance      *int          `json:"effect_chance,omitempty"`
+	EffectEntries     []Effect      `json:"effect_entries,omitempty"`
+	FlavorTextEntries []struct {
+		FlavorText   string        `json:"flavor_text,omitempty"`
+		Language     NamedResource `json:"language,omitempty"`
+		VersionGroup NamedResource `json:"version_group,omitempty"`
+	} `json:"flavor_text_entries,omitempty"`
+	Generation NamedResource `json:"generation,omitempty"`
+	ID         int           `json:"id,omitempty"`
+	Name       string        `json:"name,omitempty"`
+	Names      []Name        `json:"names,omitempty"`
+	Power      *int          `json:"power,omitempty"`
+	PP         *int          `json:"pp,omitempty"`
+	Priority   int           `json:"priority,omitempty"`
+	Target     NamedResource `json:"target,omitempty"`
+	Type       NamedResource `json:"type,omitempty"`
+}
+
+// Effect describes what a move, ability or item does in one language
+type Effect struct {
+	Effect      string        `json:"effect,omitempty"`
+	Language    NamedResource `json:"language,omitempty"`
+	ShortEffect string        `json:"short_effect,omitempty"`
+}
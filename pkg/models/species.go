@@ -0,0 +1,83 @@
+package models
+
+// ApiResource is a link to another api resource that has no name, e.g. an evolution chain
+type ApiResource struct {
+	URL string `json:"url,omitempty"`
+}
+
+type PokemonSpecies struct {
+	BaseHappiness      int             `json:"base_happiness,omitempty"`
+	CaptureRate        int             `json:"capture_rate,omitempty"`
+	Color              NamedResource   `json:"color,omitempty"`
+	EggGroups          []NamedResource `json:"egg_groups,omitempty"`
+	EvolutionChain     ApiResource     `json:"evolution_chain,omitempty"`
+	EvolvesFromSpecies NamedResource   `json:"evolves_from_species,omitempty"`
+	FlavorTextEntries  []struct {
+		FlavorText string        `json:"flavor_text,omitempty"`
+		Language   NamedResource `json:"language,omitempty"`
+		Version    NamedResource `json:"version,omitempty"`
+	} `json:"flavor_text_entries,omitempty"`
+	FormsSwitchable bool `json:"forms_switchable,omitempty"`
+	GenderRate      int  `json:"gender_rate,omitempty"`
+	Genera          []struct {
+		Genus    string        `json:"genus,omitempty"`
+		Language NamedResource `json:"language,omitempty"`
+	} `json:"genera,omitempty"`
+	Generation           NamedResource `json:"generation,omitempty"`
+	GrowthRate           NamedResource `json:"growth_rate,omitempty"`
+	Habitat              NamedResource `json:"habitat,omitempty"`
+	HasGenderDifferences bool          `json:"has_gender_differences,omitempty"`
+	HatchCounter         int           `json:"hatch_counter,omitempty"`
+	ID                   int           `json:"id,omitempty"`
+	IsBaby               bool          `json:"is_baby,omitempty"`
+	IsLegendary          bool          `json:"is_legendary,omitempty"`
+	IsMythical           bool          `json:"is_mythical,omitempty"`
+	Name                 string        `json:"name,omitempty"`
+	Names                []Name        `json:"names,omitempty"`
+	Order                int           `json:"order,omitempty"`
+	PokedexNumbers       []struct {
+		EntryNumber int           `json:"entry_number,omitempty"`
+		Pokedex     NamedResource `json:"pokedex,omitempty"`
+	} `json:"pokedex_numbers,omitempty"`
+	Shape     NamedResource `json:"shape,omitempty"`
+	Varieties []struct {
+		IsDefault bool          `json:"is_default,omitempty"`
+		Pokemon   NamedResource `json:"pokemon,omitempty"`
+	} `json:"varieties,omitempty"`
+}
+
+type EvolutionChain struct {
+	BabyTriggerItem NamedResource `json:"baby_trigger_item,omitempty"`
+	Chain           ChainLink     `json:"chain,omitempty"`
+	ID              int           `json:"id,omitempty"`
+}
+
+// ChainLink is one species in an evolution chain and the species it evolves into
+type ChainLink struct {
+	EvolutionDetails []EvolutionDetail `json:"evolution_details,omitempty"`
+	EvolvesTo        []ChainLink       `json:"evolves_to,omitempty"`
+	IsBaby           bool              `json:"is_baby,omitempty"`
+	Species          NamedResource     `json:"species,omitempty"`
+}
+
+// EvolutionDetail holds the conditions under which a species evolves, unset conditions are zero
+type EvolutionDetail struct {
+	Gender                int           `json:"gender,omitempty"`
+	HeldItem              NamedResource `json:"held_item,omitempty"`
+	Item                  NamedResource `json:"item,omitempty"`
+	KnownMove             NamedResource `json:"known_move,omitempty"`
+	KnownMoveType         NamedResource `json:"known_move_type,omitempty"`
+	Location              NamedResource `json:"location,omitempty"`
+	MinAffection          int           `json:"min_affection,omitempty"`
+	MinBeauty             int           `json:"min_beauty,omitempty"`
+	MinHappiness          int           `json:"min_happiness,omitempty"`
+	MinLevel              int           `json:"min_level,omitempty"`
+	NeedsOverworldRain    bool          `json:"needs_overworld_rain,omitempty"`
+	PartySpecies          NamedResource `json:"party_species,omitempty"`
+	PartyType             NamedResource `json:"party_type,omitempty"`
+	RelativePhysicalStats int           `json:"relative_physical_stats,omitempty"`
+	TimeOfDay             string        `json:"time_of_day,omitempty"`
+	TradeSpecies          NamedResource `json:"trade_species,omitempty"`
+	Trigger               NamedResource `json:"trigger,omitempty"`
+	TurnUpsideDown        bool          `json:"turn_upside_down,omitempty"`
+}
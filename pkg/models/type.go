@@ -0,0 +1,27 @@
+package models
+
+// Type is an elemental type such as fire or water, DamageRelations decides how
+// effective moves of the type are against other types
+type Type struct {
+	DamageRelations TypeRelations   `json:"damage_relations,omitempty"`
+	Generation      NamedResource   `json:"generation,omitempty"`
+	ID              int             `json:"id,omitempty"`
+	MoveDamageClass NamedResource   `json:"move_damage_class,omitempty"`
+	Moves           []NamedResource `json:"moves,omitempty"`
+	Name            string          `json:"name,omitempty"`
+	Names           []Name          `json:"names,omitempty"`
+	Pokemon         []struct {
+		Pokemon NamedResource `json:"pokemon,omitempty"`
+		Slot    int           `json:"slot,omitempty"`
+	} `json:"pokemon,omitempty"`
+}
+
+// TypeRelations lists the types a type deals and takes double, half and no damage from
+type TypeRelations struct {
+	DoubleDamageFrom []NamedResource `json:"double_damage_from,omitempty"`
+	DoubleDamageTo   []NamedResource `json:"double_damage_to,omitempty"`
+	HalfDamageFrom   []NamedResource `json:"half_damage_from,omitempty"`
+	HalfDamageTo     []NamedResource `json:"half_damage_to,omitempty"`
+	NoDamageFrom     []NamedResource `json:"no_damage_from,omitempty"`
+	NoDamageTo       []NamedResource `json:"no_damage_to,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/joshhartwig/pokedex/internal/database"
@@ -10,19 +11,56 @@ import (
 type CliCommand struct {
 	Name        string
 	Description string
-	Callback    func(...string) error
+	Callback    func(context.Context, ...string) error
 }
 
 type Config struct {
-	Commands   map[string]CliCommand
-	Next       string
-	Previous   string
-	BaseApiUrl string
-	Cache      pokecache.Cache
-	Pokedex    map[string]Pokemon
-	Db         *database.Queries
-	Logger     *slog.Logger
-	History    []string
+	Commands map[string]CliCommand
+	MapPage  *PageCursor     // page shown by the last map or mapb, nil before the first
+	ApiRoot  string          // root url of the PokeAPI, every resource url is derived from it
+	Cache    pokecache.Store // shared with Client, the stores are pointers so the config can be copied
+	Client   ApiClient
+	Language string // language names are shown in, empty shows the api slugs
+	Pokedex  map[string]Pokemon
+	Db       database.Querier
+	Logger   *slog.Logger
+	History  []string
+}
+
+// ApiClient is the typed PokeAPI client used by the commands. The http backed
+// implementation lives in internal/api (api.Client), it is declared here so the
+// Config can hold it without an import cycle.
+type ApiClient interface {
+	// ListLocationAreas returns the page of location areas the cursor points at
+	ListLocationAreas(ctx context.Context, page PageCursor) (Apiheader, error)
+	// GetLocationArea returns a single location area by name or id
+	GetLocationArea(ctx context.Context, name string) (LocationArea, error)
+	// GetPokemon returns a single pokemon by name or id
+	GetPokemon(ctx context.Context, name string) (Pokemon, error)
+	// GetPokemonSpecies returns the species of a pokemon by name or id
+	GetPokemonSpecies(ctx context.Context, name string) (PokemonSpecies, error)
+	// GetType returns a single type by name or id
+	GetType(ctx context.Context, name string) (Type, error)
+	// GetMove returns a single move by name or id
+	GetMove(ctx context.Context, name string) (Move, error)
+	// GetAbility returns a single ability by name or id
+	GetAbility(ctx context.Context, name string) (Ability, error)
+	// GetItem returns a single item by name or id
+	GetItem(ctx context.Context, name string) (Item, error)
+	// Fetch decodes the api resource at url into v, used to follow NamedResource links
+	Fetch(ctx context.Context, url string, v any) error
+}
+
+// Name is the name of a resource in one language
+type Name struct {
+	Language NamedResource `json:"language,omitempty"`
+	Name     string        `json:"name,omitempty"`
+}
+
+// NamedResource is a link to another api resource, it is followed with api.Resolve
+type NamedResource struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
 }
 
 // json decoding
@@ -38,117 +76,89 @@ type Apiheader struct {
 	Results  []Location `json:"results"`
 }
 
+// DefaultPageLimit is the page size the api uses when none is given
+const DefaultPageLimit = 20
+
+// PageCursor points at a page of a paginated list endpoint by offset and limit
+type PageCursor struct {
+	Offset int
+	Limit  int
+}
+
+// Size returns the limit of the page, DefaultPageLimit if it is not set
+func (p PageCursor) Size() int {
+	if p.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	return p.Limit
+}
+
+// Next returns the cursor of the following page
+func (p PageCursor) Next() PageCursor {
+	return PageCursor{Offset: p.Offset + p.Size(), Limit: p.Size()}
+}
+
+// Prev returns the cursor of the previous page, the first page has no previous page
+// and returns itself
+func (p PageCursor) Prev() PageCursor {
+	return PageCursor{Offset: max(p.Offset-p.Size(), 0), Limit: p.Size()}
+}
+
+// IsFirst reports if the cursor points at the first page
+func (p PageCursor) IsFirst() bool {
+	return p.Offset <= 0
+}
+
 type LocationArea struct {
 	EncounterMethodRates []struct {
-		EncounterMethod struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"encounter_method,omitempty"`
-		VersionDetails []struct {
-			Rate    int `json:"rate,omitempty"`
-			Version struct {
-				Name string `json:"name,omitempty"`
-				URL  string `json:"url,omitempty"`
-			} `json:"version,omitempty"`
+		EncounterMethod NamedResource `json:"encounter_method,omitempty"`
+		VersionDetails  []struct {
+			Rate    int           `json:"rate,omitempty"`
+			Version NamedResource `json:"version,omitempty"`
 		} `json:"version_details,omitempty"`
 	} `json:"encounter_method_rates,omitempty"`
-	GameIndex int `json:"game_index,omitempty"`
-	ID        int `json:"id,omitempty"`
-	Location  struct {
-		Name string `json:"name,omitempty"`
-		URL  string `json:"url,omitempty"`
-	} `json:"location,omitempty"`
-	Name  string `json:"name,omitempty"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"language,omitempty"`
-		Name string `json:"name,omitempty"`
-	} `json:"names,omitempty"`
+	GameIndex         int           `json:"game_index,omitempty"`
+	ID                int           `json:"id,omitempty"`
+	Location          NamedResource `json:"location,omitempty"`
+	Name              string        `json:"name,omitempty"`
+	Names             []Name        `json:"names,omitempty"`
 	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"pokemon,omitempty"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int   `json:"chance,omitempty"`
-				ConditionValues []any `json:"condition_values,omitempty"`
-				MaxLevel        int   `json:"max_level,omitempty"`
-				Method          struct {
-					Name string `json:"name,omitempty"`
-					URL  string `json:"url,omitempty"`
-				} `json:"method,omitempty"`
-				MinLevel int `json:"min_level,omitempty"`
-			} `json:"encounter_details,omitempty"`
-			MaxChance int `json:"max_chance,omitempty"`
-			Version   struct {
-				Name string `json:"name,omitempty"`
-				URL  string `json:"url,omitempty"`
-			} `json:"version,omitempty"`
-		} `json:"version_details,omitempty"`
+		Pokemon        NamedResource            `json:"pokemon,omitempty"`
+		VersionDetails []VersionEncounterDetail `json:"version_details,omitempty"`
 	} `json:"pokemon_encounters,omitempty"`
 }
 
 type Pokemon struct {
 	Abilities []struct {
-		Ability struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"ability,omitempty"`
-		IsHidden bool `json:"is_hidden,omitempty"`
-		Slot     int  `json:"slot,omitempty"`
+		Ability  NamedResource `json:"ability,omitempty"`
+		IsHidden bool          `json:"is_hidden,omitempty"`
+		Slot     int           `json:"slot,omitempty"`
 	} `json:"abilities,omitempty"`
 	BaseExperience int `json:"base_experience,omitempty"`
 	Cries          struct {
 		Latest string `json:"latest,omitempty"`
 		Legacy string `json:"legacy,omitempty"`
 	} `json:"cries,omitempty"`
-	Forms []struct {
-		Name string `json:"name,omitempty"`
-		URL  string `json:"url,omitempty"`
-	} `json:"forms,omitempty"`
+	Forms       []NamedResource `json:"forms,omitempty"`
 	GameIndices []struct {
-		GameIndex int `json:"game_index,omitempty"`
-		Version   struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"version,omitempty"`
+		GameIndex int           `json:"game_index,omitempty"`
+		Version   NamedResource `json:"version,omitempty"`
 	} `json:"game_indices,omitempty"`
 	Height    int `json:"height,omitempty"`
 	HeldItems []struct {
-		Item struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"item,omitempty"`
-		VersionDetails []struct {
-			Rarity  int `json:"rarity,omitempty"`
-			Version struct {
-				Name string `json:"name,omitempty"`
-				URL  string `json:"url,omitempty"`
-			} `json:"version,omitempty"`
-		} `json:"version_details,omitempty"`
+		Item           NamedResource   `json:"item,omitempty"`
+		VersionDetails []VersionRarity `json:"version_details,omitempty"`
 	} `json:"held_items,omitempty"`
 	ID                     int    `json:"id,omitempty"`
 	IsDefault              bool   `json:"is_default,omitempty"`
 	LocationAreaEncounters string `json:"location_area_encounters,omitempty"`
 	Moves                  []struct {
-		Move struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"move,omitempty"`
+		Move                NamedResource `json:"move,omitempty"`
 		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at,omitempty"`
-			MoveLearnMethod struct {
-				Name string `json:"name,omitempty"`
-				URL  string `json:"url,omitempty"`
-			} `json:"move_learn_method,omitempty"`
-			Order        int `json:"order,omitempty"`
-			VersionGroup struct {
-				Name string `json:"name,omitempty"`
-				URL  string `json:"url,omitempty"`
-			} `json:"version_group,omitempty"`
+			LevelLearnedAt  int           `json:"level_learned_at,omitempty"`
+			MoveLearnMethod NamedResource `json:"move_learn_method,omitempty"`
+			Order           int           `json:"order,omitempty"`
+			VersionGroup    NamedResource `json:"version_group,omitempty"`
 		} `json:"version_group_details,omitempty"`
 	} `json:"moves,omitempty"`
 	Name          string `json:"name,omitempty"`
@@ -159,17 +169,11 @@ type Pokemon struct {
 			IsHidden bool `json:"is_hidden,omitempty"`
 			Slot     int  `json:"slot,omitempty"`
 		} `json:"abilities,omitempty"`
-		Generation struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"generation,omitempty"`
+		Generation NamedResource `json:"generation,omitempty"`
 	} `json:"past_abilities,omitempty"`
-	PastTypes []any `json:"past_types,omitempty"`
-	Species   struct {
-		Name string `json:"name,omitempty"`
-		URL  string `json:"url,omitempty"`
-	} `json:"species,omitempty"`
-	Sprites struct {
+	PastTypes []any         `json:"past_types,omitempty"`
+	Species   NamedResource `json:"species,omitempty"`
+	Sprites   struct {
 		BackDefault      string `json:"back_default,omitempty"`
 		BackFemale       any    `json:"back_female,omitempty"`
 		BackShiny        string `json:"back_shiny,omitempty"`
@@ -356,19 +360,13 @@ type Pokemon struct {
 		} `json:"versions,omitempty"`
 	} `json:"sprites,omitempty"`
 	Stats []struct {
-		BaseStat int `json:"base_stat,omitempty"`
-		Effort   int `json:"effort,omitempty"`
-		Stat     struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"stat,omitempty"`
+		BaseStat int           `json:"base_stat,omitempty"`
+		Effort   int           `json:"effort,omitempty"`
+		Stat     NamedResource `json:"stat,omitempty"`
 	} `json:"stats,omitempty"`
 	Types []struct {
-		Slot int `json:"slot,omitempty"`
-		Type struct {
-			Name string `json:"name,omitempty"`
-			URL  string `json:"url,omitempty"`
-		} `json:"type,omitempty"`
+		Slot int           `json:"slot,omitempty"`
+		Type NamedResource `json:"type,omitempty"`
 	} `json:"types,omitempty"`
 	Weight int `json:"weight,omitempty"`
 }
@@ -0,0 +1,22 @@
+-- name: GetCacheEntry :one
+SELECT * FROM cache_entries
+WHERE key = $1
+LIMIT 1;
+
+-- name: UpsertCacheEntry :exec
+INSERT INTO cache_entries (key, val, etag, last_modified, created_at, ttl_ms)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (key) DO UPDATE
+SET val = EXCLUDED.val,
+    etag = EXCLUDED.etag,
+    last_modified = EXCLUDED.last_modified,
+    created_at = EXCLUDED.created_at,
+    ttl_ms = EXCLUDED.ttl_ms;
+
+-- name: DeleteCacheEntry :exec
+DELETE FROM cache_entries
+WHERE key = $1;
+
+-- name: ListCacheEntries :many
+SELECT * FROM cache_entries
+ORDER BY key;
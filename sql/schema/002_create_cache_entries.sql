@@ -0,0 +1,13 @@
+-- +goose Up
+
+CREATE TABLE cache_entries (
+    key TEXT PRIMARY KEY,
+    val BYTEA NOT NULL,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    ttl_ms BIGINT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE cache_entries;